@@ -0,0 +1,177 @@
+package siocore
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrorCode is a stable, machine-readable identifier of an error, e.g. "VALIDATION.INVALID_EMAIL".
+// Clients should switch on the code rather than on the error message.
+type ErrorCode string
+
+const (
+	CodeUnknown            ErrorCode = "UNKNOWN"
+	CodeDecryptionFailed   ErrorCode = "CRYPTO.DECRYPTION_FAILED"
+	CodeInvalidEmail       ErrorCode = "VALIDATION.INVALID_EMAIL"
+	CodeInvalidPassword    ErrorCode = "VALIDATION.INVALID_PASSWORD"
+	CodeInvalidName        ErrorCode = "VALIDATION.INVALID_NAME"
+	CodeInvalidDescription ErrorCode = "VALIDATION.INVALID_DESCRIPTION"
+	CodeInvalidPhone       ErrorCode = "VALIDATION.INVALID_PHONE"
+	CodeInvalidDob         ErrorCode = "VALIDATION.INVALID_DOB"
+	CodeInvalidAge         ErrorCode = "VALIDATION.INVALID_AGE"
+	CodeInvalidEndDate     ErrorCode = "VALIDATION.INVALID_END_DATE"
+	CodeInvalidID          ErrorCode = "VALIDATION.INVALID_ID"
+	CodeRequired           ErrorCode = "VALIDATION.REQUIRED"
+	CodeTokenInvalid       ErrorCode = "AUTH.TOKEN_INVALID"
+)
+
+// CatalogEntry describes an error known to the catalog.
+// Message and the Localized messages are fmt templates rendered with the arguments given
+// when the error is created. Localized is keyed by language tag, e.g. "es" or "pt-BR".
+type CatalogEntry struct {
+	Code      ErrorCode
+	Status    int
+	Message   string
+	Localized map[string]string
+}
+
+// Localize renders the message for the given language. Regional tags fall back to their
+// base language ("es-MX" to "es") and then to the default Message.
+func (ce CatalogEntry) Localize(lang string, args ...any) string {
+	template := ce.Message
+
+	if msg, ok := ce.Localized[lang]; ok {
+		template = msg
+	} else if base, _, found := strings.Cut(lang, "-"); found {
+		if msg, ok := ce.Localized[base]; ok {
+			template = msg
+		}
+	}
+
+	return renderTemplate(template, args)
+}
+
+// New creates an AppError for the entry, rendering the default message with args.
+func (ce CatalogEntry) New(args ...any) *AppError {
+	return ce.newAppErrorAt(1, nil, args)
+}
+
+// Wrap creates an AppError for the entry that wraps err. Wrap returns nil if err is nil.
+func (ce CatalogEntry) Wrap(err error, args ...any) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	return ce.newAppErrorAt(1, err, args)
+}
+
+// newAppErrorAt creates the AppError for the entry, recording the stack starting depth
+// frames above its caller.
+func (ce CatalogEntry) newAppErrorAt(depth int, cause error, args []any) *AppError {
+	appErr := newAppErrorAt(depth+1, renderTemplate(ce.Message, args), ce.Status, cause)
+	appErr.ErrorCode = ce.Code
+	appErr.args = args
+
+	return appErr
+}
+
+// ErrorCatalog is a registry of CatalogEntry values keyed by their ErrorCode.
+// It is safe for concurrent use.
+type ErrorCatalog struct {
+	mu      sync.RWMutex
+	entries map[ErrorCode]CatalogEntry
+}
+
+// NewErrorCatalog creates a catalog holding the given entries.
+func NewErrorCatalog(entries ...CatalogEntry) *ErrorCatalog {
+	c := &ErrorCatalog{entries: make(map[ErrorCode]CatalogEntry, len(entries))}
+	c.Register(entries...)
+
+	return c
+}
+
+// Register adds entries to the catalog, replacing any entry with the same code.
+func (c *ErrorCatalog) Register(entries ...CatalogEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range entries {
+		c.entries[entry.Code] = entry
+	}
+}
+
+// Lookup returns the entry registered for code.
+func (c *ErrorCatalog) Lookup(code ErrorCode) (CatalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[code]
+	return entry, ok
+}
+
+// New creates an AppError for the entry registered for code. An unregistered code yields
+// a 500 AppError carrying that code.
+func (c *ErrorCatalog) New(code ErrorCode, args ...any) *AppError {
+	return c.entryOrUnknown(code).newAppErrorAt(1, nil, args)
+}
+
+// Localize renders the message of err in the given language using the catalog entry for its
+// ErrorCode. Errors without a registered code keep their own message.
+func (c *ErrorCatalog) Localize(err *AppError, lang string) string {
+	entry, ok := c.Lookup(err.ErrorCode)
+	if !ok {
+		return err.Message
+	}
+
+	return entry.Localize(lang, err.args...)
+}
+
+func (c *ErrorCatalog) entryOrUnknown(code ErrorCode) CatalogEntry {
+	if entry, ok := c.Lookup(code); ok {
+		return entry
+	}
+
+	return CatalogEntry{
+		Code:    code,
+		Status:  http.StatusInternalServerError,
+		Message: http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// DefaultCatalog holds the entries for the messages defined in errors.go.
+var DefaultCatalog = NewErrorCatalog(
+	CatalogEntry{Code: CodeDecryptionFailed, Status: http.StatusInternalServerError, Message: DecryptionFailedMsg},
+	CatalogEntry{Code: CodeInvalidEmail, Status: http.StatusBadRequest, Message: InvalidEmailErr},
+	CatalogEntry{Code: CodeInvalidPassword, Status: http.StatusBadRequest, Message: InvalidPasswordErr},
+	CatalogEntry{Code: CodeInvalidName, Status: http.StatusBadRequest, Message: InvalidNameErr},
+	CatalogEntry{Code: CodeInvalidDescription, Status: http.StatusBadRequest, Message: InvalidDescriptionErr},
+	CatalogEntry{Code: CodeInvalidPhone, Status: http.StatusBadRequest, Message: InvalidPhoneErr},
+	CatalogEntry{Code: CodeInvalidDob, Status: http.StatusBadRequest, Message: InvalidDobErr},
+	CatalogEntry{Code: CodeInvalidAge, Status: http.StatusBadRequest, Message: InvalidAgeErr},
+	CatalogEntry{Code: CodeInvalidEndDate, Status: http.StatusBadRequest, Message: InvalidEndDateErr},
+	CatalogEntry{Code: CodeInvalidID, Status: http.StatusBadRequest, Message: INVALID_ID},
+	CatalogEntry{Code: CodeRequired, Status: http.StatusBadRequest, Message: RequiredErr},
+	CatalogEntry{Code: CodeTokenInvalid, Status: http.StatusUnauthorized, Message: TokenInvalid},
+)
+
+// RegisterErrors adds entries to the DefaultCatalog.
+func RegisterErrors(entries ...CatalogEntry) {
+	DefaultCatalog.Register(entries...)
+}
+
+// NewCodedError creates an AppError for code from the DefaultCatalog.
+func NewCodedError(code ErrorCode, args ...any) *AppError {
+	return DefaultCatalog.entryOrUnknown(code).newAppErrorAt(1, nil, args)
+}
+
+// renderTemplate only formats the template when there are arguments, so a message
+// containing a literal % is left untouched.
+func renderTemplate(template string, args []any) string {
+	if len(args) == 0 {
+		return template
+	}
+
+	return fmt.Sprintf(template, args...)
+}
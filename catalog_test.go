@@ -0,0 +1,102 @@
+package siocore
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCodedError(t *testing.T) {
+	err := NewCodedError(CodeInvalidEmail)
+
+	assert.Equal(t, CodeInvalidEmail, err.ErrorCode)
+	assert.Equal(t, 400, err.Code)
+	assert.Equal(t, InvalidEmailErr, err.Error())
+	assert.True(t, strings.HasSuffix(err.StackTrace()[0].Function, "TestNewCodedError"))
+}
+
+func TestNewCodedError_Unknown(t *testing.T) {
+	err := NewCodedError("SOMETHING.ELSE")
+
+	assert.Equal(t, ErrorCode("SOMETHING.ELSE"), err.ErrorCode)
+	assert.Equal(t, 500, err.Code)
+}
+
+func TestErrorCatalog(t *testing.T) {
+	entry := CatalogEntry{
+		Code:    "ORDER.NOT_FOUND",
+		Status:  404,
+		Message: "order %s not found",
+		Localized: map[string]string{
+			"es":    "pedido %s no encontrado",
+			"pt-BR": "pedido %s não encontrado",
+		},
+	}
+	catalog := NewErrorCatalog(entry)
+
+	got, ok := catalog.Lookup("ORDER.NOT_FOUND")
+	assert.True(t, ok)
+	assert.Equal(t, entry, got)
+
+	_, ok = catalog.Lookup(CodeInvalidEmail)
+	assert.False(t, ok)
+
+	err := catalog.New("ORDER.NOT_FOUND", "o-1")
+	assert.Equal(t, "order o-1 not found", err.Error())
+	assert.Equal(t, 404, err.Code)
+	assert.True(t, strings.HasSuffix(err.StackTrace()[0].Function, "TestErrorCatalog"))
+
+	tt := []struct {
+		lang     string
+		expected string
+	}{
+		{lang: "es", expected: "pedido o-1 no encontrado"},
+		{lang: "es-MX", expected: "pedido o-1 no encontrado"},
+		{lang: "pt-BR", expected: "pedido o-1 não encontrado"},
+		{lang: "fr", expected: "order o-1 not found"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.lang, func(t *testing.T) {
+			assert.Equal(t, tc.expected, catalog.Localize(err, tc.lang))
+		})
+	}
+}
+
+func TestCatalogEntry_Wrap(t *testing.T) {
+	entry, ok := DefaultCatalog.Lookup(CodeDecryptionFailed)
+	assert.True(t, ok)
+
+	cause := assert.AnError
+	err := entry.Wrap(cause)
+
+	assert.Equal(t, CodeDecryptionFailed, err.ErrorCode)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, DecryptionFailedMsg, err.Message, "the cause stays out of the message")
+	assert.Nil(t, entry.Wrap(nil))
+
+	assert.Equal(t, DecryptionFailedMsg, NewCodedError(CodeDecryptionFailed).Message)
+}
+
+func TestCatalogEntry_LiteralPercent(t *testing.T) {
+	entry := CatalogEntry{Code: "X", Status: 400, Message: "100% invalid"}
+
+	assert.Equal(t, "100% invalid", entry.New().Error())
+}
+
+func TestAppError_ProblemCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, NewCodedError(CodeTokenInvalid))
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, string(CodeTokenInvalid), body["code"])
+	assert.Equal(t, float64(401), body["status"])
+
+	parsed, err := ParseProblemDetails(rec.Body.Bytes(), rec.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeTokenInvalid, parsed.ErrorCode)
+	assert.Nil(t, parsed.Details)
+}
@@ -0,0 +1,217 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slausonio/siocore"
+)
+
+const (
+	// version prefixes every ciphertext so the format can evolve.
+	version   = "v1"
+	separator = "."
+)
+
+var ErrInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+
+var encoding = base64.RawURLEncoding
+
+// Encryptor encrypts values with a fresh data key each, which is itself encrypted, or
+// wrapped, with the primary key of its Keyring. A ciphertext has the form
+//
+//	v1.<key id>.<wrapped data key>.<sealed value>
+//
+// so it can be decrypted by any Keyring holding the key it was wrapped with.
+type Encryptor struct {
+	keyring *Keyring
+}
+
+// New creates an Encryptor using keyring.
+func New(keyring *Keyring) *Encryptor {
+	return &Encryptor{keyring: keyring}
+}
+
+// NewFromEnv creates an Encryptor with the keys configured in env, see KeyringFromEnv.
+func NewFromEnv(env siocore.Env) (*Encryptor, error) {
+	keyring, err := KeyringFromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(keyring), nil
+}
+
+// Encrypt encrypts plaintext under a new data key wrapped with the primary key.
+func (e *Encryptor) Encrypt(plaintext string) (string, error) {
+	dataKey, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := e.wrap(e.keyring.primary, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(data, []byte(plaintext), []byte(version))
+	if err != nil {
+		return "", err
+	}
+
+	return format(e.keyring.primary, wrapped, sealed), nil
+}
+
+// Decrypt decrypts a ciphertext created by Encrypt. Failures are siocore.AppErrors with
+// the siocore.CodeDecryptionFailed code wrapping the cause.
+func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
+	plaintext, err := e.decrypt(ciphertext)
+	if err != nil {
+		return "", decryptionFailed(err)
+	}
+
+	return plaintext, nil
+}
+
+// EncryptEnvValue encrypts plaintext as an ENC(...) value for an env file.
+func (e *Encryptor) EncryptEnvValue(plaintext string) (string, error) {
+	ciphertext, err := e.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	return siocore.EncryptedValue(ciphertext), nil
+}
+
+// Rewrap re-encrypts the data key of ciphertext with the primary key, leaving the sealed
+// value untouched. Rewrapping every stored value completes a key rotation.
+func (e *Encryptor) Rewrap(ciphertext string) (string, error) {
+	keyID, wrapped, sealed, err := parse(ciphertext)
+	if err != nil {
+		return "", decryptionFailed(err)
+	}
+	if keyID == e.keyring.primary {
+		return ciphertext, nil
+	}
+
+	dataKey, err := e.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", decryptionFailed(err)
+	}
+
+	rewrapped, err := e.wrap(e.keyring.primary, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return format(e.keyring.primary, rewrapped, sealed), nil
+}
+
+// KeyID returns the id of the key ciphertext was encrypted with.
+func KeyID(ciphertext string) (string, error) {
+	keyID, _, _, err := parse(ciphertext)
+	return keyID, err
+}
+
+func (e *Encryptor) decrypt(ciphertext string) (string, error) {
+	keyID, wrapped, sealed, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := e.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+
+	plaintext, err := open(data, sealed, []byte(version))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// wrap encrypts dataKey with the key keyID, binding the key id to the result.
+func (e *Encryptor) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	kek, err := e.keyring.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(kek, dataKey, []byte(version+separator+keyID))
+}
+
+func (e *Encryptor) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	kek, err := e.keyring.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(kek, wrapped, []byte(version+separator+keyID))
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the result.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+
+	return plaintext, nil
+}
+
+func format(keyID string, wrapped, sealed []byte) string {
+	return strings.Join([]string{version, keyID, encoding.EncodeToString(wrapped), encoding.EncodeToString(sealed)}, separator)
+}
+
+func parse(ciphertext string) (keyID string, wrapped, sealed []byte, err error) {
+	parts := strings.Split(ciphertext, separator)
+	if len(parts) != 4 || parts[0] != version || parts[1] == "" {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	if wrapped, err = encoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+	if sealed, err = encoding.DecodeString(parts[3]); err != nil {
+		return "", nil, nil, fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+
+	return parts[1], wrapped, sealed, nil
+}
+
+// decryptionFailed wraps err in an AppError with the fixed DecryptionFailedMsg message, so the
+// cause is only reachable through Unwrap and never sent to clients.
+func decryptionFailed(err error) error {
+	entry, _ := siocore.DefaultCatalog.Lookup(siocore.CodeDecryptionFailed)
+	return entry.Wrap(err)
+}
@@ -0,0 +1,269 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slausonio/siocore"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	return key
+}
+
+func testEncryptor(t *testing.T, primary string, keys map[string][]byte) *Encryptor {
+	t.Helper()
+
+	keyring, err := NewKeyring(primary, keys)
+	assert.NoError(t, err)
+
+	return New(keyring)
+}
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+
+	for _, plaintext := range []string{"", "hunter2", "üñïcødé with . and : and ENC()"} {
+		ciphertext, err := enc.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.NotContains(t, ciphertext, "hunter2")
+
+		keyID, err := KeyID(ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", keyID)
+
+		got, err := enc.Decrypt(ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	}
+
+	a, _ := enc.Encrypt("same")
+	b, _ := enc.Encrypt("same")
+	assert.NotEqual(t, a, b, "every value gets its own data key and nonce")
+}
+
+func TestEncryptor_Decrypt_Errors(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+	other := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+	ciphertext, err := enc.Encrypt("hunter2")
+	assert.NoError(t, err)
+
+	parts := strings.Split(ciphertext, ".")
+	sealed, _ := base64.RawURLEncoding.DecodeString(parts[3])
+	sealed[len(sealed)-1] ^= 0xff
+	tampered := strings.Join([]string{parts[0], parts[1], parts[2], base64.RawURLEncoding.EncodeToString(sealed)}, ".")
+
+	tests := []struct {
+		name       string
+		enc        *Encryptor
+		ciphertext string
+		wantErr    error
+	}{
+		{name: "malformed", enc: enc, ciphertext: "not-a-ciphertext", wantErr: ErrInvalidCiphertext},
+		{name: "bad encoding", enc: enc, ciphertext: "v1.k1.***.***", wantErr: ErrInvalidCiphertext},
+		{name: "unknown key", enc: enc, ciphertext: strings.Replace(ciphertext, ".k1.", ".k9.", 1), wantErr: ErrUnknownKey},
+		{name: "wrong key", enc: other, ciphertext: ciphertext, wantErr: ErrInvalidCiphertext},
+		{name: "tampered", enc: enc, ciphertext: tampered, wantErr: ErrInvalidCiphertext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.enc.Decrypt(tt.ciphertext)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			var appErr *siocore.AppError
+			if assert.True(t, errors.As(err, &appErr)) {
+				assert.Equal(t, siocore.CodeDecryptionFailed, appErr.ErrorCode)
+				assert.Equal(t, siocore.DecryptionFailedMsg, appErr.Message, "the cause is not part of the message")
+				assert.Equal(t, siocore.DecryptionFailedMsg, appErr.ProblemDetails().Detail)
+			}
+		})
+	}
+}
+
+func TestEncryptor_Rotation(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	before := testEncryptor(t, "2023", map[string][]byte{"2023": oldKey})
+	after := testEncryptor(t, "2024", map[string][]byte{"2024": newKey, "2023": oldKey})
+
+	ciphertext, err := before.Encrypt("hunter2")
+	assert.NoError(t, err)
+
+	got, err := after.Decrypt(ciphertext)
+	assert.NoError(t, err, "old keys still decrypt")
+	assert.Equal(t, "hunter2", got)
+
+	rewrapped, err := after.Rewrap(ciphertext)
+	assert.NoError(t, err)
+	keyID, _ := KeyID(rewrapped)
+	assert.Equal(t, "2024", keyID)
+	assert.Equal(t, strings.Split(ciphertext, ".")[3], strings.Split(rewrapped, ".")[3], "the sealed value is kept")
+
+	retired := testEncryptor(t, "2024", map[string][]byte{"2024": newKey})
+	got, err = retired.Decrypt(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", got)
+
+	unchanged, err := after.Rewrap(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, rewrapped, unchanged)
+}
+
+func TestNewKeyring_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary string
+		keys    map[string][]byte
+		wantErr error
+	}{
+		{name: "no keys", primary: "k1", wantErr: ErrNoKeys},
+		{name: "short key", primary: "k1", keys: map[string][]byte{"k1": []byte("short")}, wantErr: ErrInvalidKey},
+		{name: "bad id", primary: "k.1", keys: map[string][]byte{"k.1": make([]byte, KeySize)}, wantErr: ErrInvalidKeyID},
+		{name: "unknown primary", primary: "k2", keys: map[string][]byte{"k1": make([]byte, KeySize)}, wantErr: ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.primary, tt.keys)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestKeyringFromEnv(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(t))
+	k2 := base64.StdEncoding.EncodeToString(testKey(t))
+
+	tests := []struct {
+		name        string
+		env         siocore.Env
+		wantPrimary string
+		wantErr     error
+	}{
+		{name: "first key is primary", env: siocore.Env{EnvKeyEncryptionKeys: "k2:" + k2 + ", k1:" + k1}, wantPrimary: "k2"},
+		{name: "explicit primary", env: siocore.Env{EnvKeyEncryptionKeys: "k2:" + k2 + ",k1:" + k1, EnvKeyEncryptionKeyID: "k1"}, wantPrimary: "k1"},
+		{name: "missing", env: siocore.Env{}, wantErr: ErrNoKeys},
+		{name: "not id:key", env: siocore.Env{EnvKeyEncryptionKeys: k1}, wantErr: ErrInvalidKey},
+		{name: "bad base64", env: siocore.Env{EnvKeyEncryptionKeys: "k1:***"}, wantErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := KeyringFromEnv(tt.env)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPrimary, keyring.PrimaryKeyID())
+		})
+	}
+}
+
+type card struct {
+	Number string `encrypted:"true"`
+	Holder string
+}
+
+type customer struct {
+	Name    string
+	SSN     string   `encrypted:"true"`
+	Note    *string  `encrypted:"true"`
+	Backup  []string `encrypted:"true"`
+	Empty   string   `encrypted:"true"`
+	Card    card
+	Primary *card
+	private string
+}
+
+func TestEncryptor_Struct(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+	note := "vip"
+	c := &customer{
+		Name:    "Ada",
+		SSN:     "123-45-6789",
+		Note:    &note,
+		Backup:  []string{"a", "b"},
+		Card:    card{Number: "4111", Holder: "Ada"},
+		Primary: &card{Number: "5500"},
+		private: "kept",
+	}
+
+	assert.NoError(t, enc.EncryptStruct(c))
+	assert.Equal(t, "Ada", c.Name)
+	assert.NotEqual(t, "123-45-6789", c.SSN)
+	assert.NotEqual(t, "vip", *c.Note)
+	assert.NotEqual(t, "a", c.Backup[0])
+	assert.Empty(t, c.Empty)
+	assert.NotEqual(t, "4111", c.Card.Number)
+	assert.Equal(t, "Ada", c.Card.Holder)
+	assert.NotEqual(t, "5500", c.Primary.Number)
+
+	assert.NoError(t, enc.DecryptStruct(c))
+	assert.Equal(t, "123-45-6789", c.SSN)
+	assert.Equal(t, "vip", *c.Note)
+	assert.Equal(t, []string{"a", "b"}, c.Backup)
+	assert.Equal(t, "4111", c.Card.Number)
+	assert.Equal(t, "5500", c.Primary.Number)
+	assert.Equal(t, "kept", c.private)
+
+	assert.ErrorIs(t, enc.EncryptStruct(customer{}), ErrInvalidTarget)
+	assert.ErrorIs(t, enc.EncryptStruct(&struct {
+		Age int `encrypted:"true"`
+	}{}), ErrUnsupportedField)
+
+	err := enc.DecryptStruct(&customer{Card: card{Number: "plain"}})
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	assert.ErrorContains(t, err, "Card.Number")
+}
+
+func TestEncryptor_DecodeRequest(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+	ssn, err := enc.Encrypt("123-45-6789")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"Ada","SSN":"`+ssn+`"}`))
+	var c customer
+	assert.NoError(t, enc.DecodeRequest(req, &c))
+	assert.Equal(t, "123-45-6789", c.SSN)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{`))
+	err = enc.DecodeRequest(req, &c)
+	assert.True(t, siocore.IsBadRequest(err))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"Ada","SSN":"v1.k1.bad.bad"}`))
+	err = enc.DecodeRequest(req, &c)
+	assert.True(t, siocore.IsBadRequest(err), "ciphertext sent by the client is a bad request")
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestLoadAppEnv_EncryptedValues(t *testing.T) {
+	enc := testEncryptor(t, "k1", map[string][]byte{"k1": testKey(t)})
+	password, err := enc.EncryptEnvValue("hunter2")
+	assert.NoError(t, err)
+
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "env"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "env/.env"), []byte("APP_NAME=crypto\nCURRENT_ENV=test\nDB_PASS="+password+"\n"), 0o644))
+	for _, key := range []string{siocore.EnvKeyAppName, siocore.EnvKeyCurrentEnv, "DB_PASS"} {
+		t.Setenv(key, "")
+		assert.NoError(t, os.Unsetenv(key))
+	}
+
+	appEnv, err := siocore.LoadAppEnv(siocore.WithBaseDir(root), siocore.WithoutSystemMutation(), siocore.WithDecrypter(enc))
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", appEnv.Env().Value("DB_PASS"))
+}
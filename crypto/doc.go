@@ -0,0 +1,2 @@
+// package crypto provides AES-GCM envelope encryption of strings, struct fields and env values
+package crypto
@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/slausonio/siocore"
+)
+
+const (
+	// EnvKeyEncryptionKeys lists the keys as comma separated id:base64 pairs, e.g.
+	// ENCRYPTION_KEYS=2024-06:<key>,2023-01:<key>.
+	EnvKeyEncryptionKeys = siocore.EnvKeyEncryptionKeys
+	// EnvKeyEncryptionKeyID selects the key new values are encrypted with. Defaults to the
+	// first key of ENCRYPTION_KEYS.
+	EnvKeyEncryptionKeyID = siocore.EnvKeyEncryptionKeyID
+
+	// KeySize is the size of the AES-256 keys.
+	KeySize = 32
+)
+
+var (
+	ErrNoKeys       = errors.New("crypto: no encryption keys")
+	ErrInvalidKey   = errors.New("crypto: invalid encryption key")
+	ErrInvalidKeyID = errors.New("crypto: invalid key id")
+	ErrUnknownKey   = errors.New("crypto: unknown key id")
+)
+
+// Keyring holds the key encryption keys by id. New values are encrypted with the primary key,
+// while every key can decrypt, so keys can be rotated by adding a new primary key and
+// rewrapping existing values before the old key is removed.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates a Keyring from AES-256 keys by id, encrypting with the key primaryID.
+func NewKeyring(primaryID string, keys map[string][]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	kr := &Keyring{primary: primaryID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if !validKeyID(id) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidKeyID, id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidKey, id, err)
+		}
+		kr.keys[id] = aead
+	}
+
+	if _, ok := kr.keys[primaryID]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primaryID)
+	}
+
+	return kr, nil
+}
+
+// KeyringFromEnv creates a Keyring from the ENCRYPTION_KEYS and ENCRYPTION_KEY_ID keys of env.
+func KeyringFromEnv(env siocore.Env) (*Keyring, error) {
+	raw, ok := env.LookupValue(EnvKeyEncryptionKeys)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not set", ErrNoKeys, EnvKeyEncryptionKeys)
+	}
+
+	keys := make(map[string][]byte)
+	var first string
+	for _, entry := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s entry is not in id:key form", ErrInvalidKey, EnvKeyEncryptionKeys)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrInvalidKey, id, err)
+		}
+
+		keys[id] = key
+		if first == "" {
+			first = id
+		}
+	}
+
+	primary, ok := env.LookupValue(EnvKeyEncryptionKeyID)
+	if !ok {
+		primary = first
+	}
+
+	return NewKeyring(primary, keys)
+}
+
+// KeyringFromSystemEnv creates a Keyring from the process environment, so it is available
+// before an AppEnv holding ENC(...) values is loaded.
+func KeyringFromSystemEnv() (*Keyring, error) {
+	env := siocore.Env{}
+	for _, key := range []string{EnvKeyEncryptionKeys, EnvKeyEncryptionKeyID} {
+		if value, ok := os.LookupEnv(key); ok {
+			env[key] = value
+		}
+	}
+
+	return KeyringFromEnv(env)
+}
+
+// PrimaryKeyID returns the id of the key new values are encrypted with.
+func (kr *Keyring) PrimaryKeyID() string {
+	return kr.primary
+}
+
+func (kr *Keyring) key(id string) (cipher.AEAD, error) {
+	aead, ok := kr.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	return aead, nil
+}
+
+// GenerateKey returns a random AES-256 key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// validKeyID reports whether id is non-empty and free of the separators used by
+// ENCRYPTION_KEYS and the ciphertext format.
+func validKeyID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, ":,.() \t\n")
+}
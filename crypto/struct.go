@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/slausonio/siocore"
+)
+
+// TagName marks the fields EncryptStruct and DecryptStruct transform, e.g.
+//
+//	type Customer struct {
+//		Name string
+//		SSN  string   `encrypted:"true"`
+//		Tags []string `encrypted:"true"`
+//	}
+const TagName = "encrypted"
+
+var (
+	ErrInvalidTarget    = errors.New("crypto: target must be a non-nil pointer to a struct")
+	ErrUnsupportedField = errors.New("crypto: encrypted fields must be strings, string pointers or string slices")
+)
+
+// EncryptStruct encrypts the tagged fields of the struct pointed to by v in place. Nested
+// structs and pointers to structs are walked recursively; empty strings are left empty.
+func (e *Encryptor) EncryptStruct(v any) error {
+	return e.transformStruct(v, e.Encrypt)
+}
+
+// DecryptStruct decrypts the tagged fields of the struct pointed to by v in place.
+func (e *Encryptor) DecryptStruct(v any) error {
+	return e.transformStruct(v, e.Decrypt)
+}
+
+// DecodeRequest decodes the JSON body of r into v and decrypts its tagged fields. A body
+// that is not valid JSON or holds a field that does not decrypt yields a bad request
+// siocore.AppError, as the client sent it.
+func (e *Encryptor) DecodeRequest(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return siocore.Wrap(err, http.StatusBadRequest, "invalid request body")
+	}
+
+	err := e.DecryptStruct(v)
+	if errors.Is(err, ErrInvalidCiphertext) || errors.Is(err, ErrUnknownKey) {
+		return siocore.Wrap(err, http.StatusBadRequest, "invalid encrypted value")
+	}
+
+	return err
+}
+
+func (e *Encryptor) transformStruct(v any, transform func(string) (string, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	return walkStruct(rv.Elem(), transform)
+}
+
+func walkStruct(rv reflect.Value, transform func(string) (string, error)) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if sf.Tag.Get(TagName) == "true" {
+			if err := transformField(fv, transform); err != nil {
+				return fmt.Errorf("%s: %w", sf.Name, err)
+			}
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := walkStruct(fv, transform); err != nil {
+				return fmt.Errorf("%s.%w", sf.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func transformField(fv reflect.Value, transform func(string) (string, error)) error {
+	switch {
+	case fv.Kind() == reflect.String:
+		if fv.String() == "" {
+			return nil
+		}
+
+		value, err := transform(fv.String())
+		if err != nil {
+			return err
+		}
+		fv.SetString(value)
+	case fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.String:
+		if fv.IsNil() {
+			return nil
+		}
+
+		return transformField(fv.Elem(), transform)
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < fv.Len(); i++ {
+			if err := transformField(fv.Index(i), transform); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrUnsupportedField
+	}
+
+	return nil
+}
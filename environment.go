@@ -1,12 +1,18 @@
 package siocore
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
-
-	"github.com/joho/godotenv"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -15,14 +21,20 @@ const (
 	EnvKeyPort       = "PORT"
 	EnvKeyLokiHost   = "LOKI_HOST"
 
+	// EnvKeyEncryptionKeys and EnvKeyEncryptionKeyID hold the crypto package's key encryption
+	// keys. They are always treated as secrets.
+	EnvKeyEncryptionKeys  = "ENCRYPTION_KEYS"
+	EnvKeyEncryptionKeyID = "ENCRYPTION_KEY_ID"
+
 	DefaultFilePath    = "env/.env"
 	CurrentEnvFilePath = "env/%s.env"
 )
 
 var (
-	ErrNoEnvFile    = errors.New("no .env file found in root of project")
-	ErrNoAppName    = errors.New("no APP_NAME env var found")
-	ErrNoCurrentEnv = errors.New("no CURRENT_ENV env var found")
+	ErrNoEnvFile      = errors.New("no .env file found in root of project")
+	ErrNoAppName      = errors.New("no APP_NAME env var found")
+	ErrNoCurrentEnv   = errors.New("no CURRENT_ENV env var found")
+	ErrInvalidEnvFile = errors.New("invalid env file")
 )
 
 // Env is a type that represents a map of string key-value pairs for environment variables.
@@ -51,8 +63,7 @@ func (e Env) LookupValue(key string) (string, bool) {
 // ValuesPresent validates required authz variables are present.  If not, the thread will panic
 func (e Env) ValuesPresent(envVarKeys []string) {
 	for _, key := range envVarKeys {
-		value, present := e.LookupValue(key)
-		if !present || value == "" {
+		if _, present := e.LookupValue(key); !present {
 			panic(
 				fmt.Sprintf(
 					"The environment variable %s is not present.\n Unable to start application.",
@@ -63,93 +74,315 @@ func (e Env) ValuesPresent(envVarKeys []string) {
 	}
 }
 
+// RequireValues is the error returning alternative to ValuesPresent. It returns an
+// *EnvKeyError wrapping ErrEnvKeyNotFound for every missing key, joined with errors.Join.
+func (e Env) RequireValues(keys ...string) error {
+	return errors.Join(e.missingKeyErrors(keys)...)
+}
+
+func (e Env) missingKeyErrors(keys []string) []error {
+	var errs []error
+	for _, key := range keys {
+		if _, present := e.LookupValue(key); !present {
+			errs = append(errs, &EnvKeyError{Key: key, Err: ErrEnvKeyNotFound})
+		}
+	}
+
+	return errs
+}
+
 // setToSystem sets the environment variables in the SioWSEnv map to the system.
-// It iterates over the key-value pairs in the map and uses os.Setenv to set each variable.
-// If there is an error setting the variable, it panics with the error.
-func (e Env) setToSystem() {
+// It iterates over the key-value pairs in the map and uses os.Setenv to set each variable,
+// skipping the keys in secrets so their plaintext does not leak to child processes.
+func (e Env) setToSystem(secrets map[string]bool) error {
 	for key, value := range e {
-		err := os.Setenv(key, value)
-		if err != nil {
-			panic(err)
+		if secrets[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
 		}
 	}
+
+	return nil
 }
 
+// AppEnv is the environment loaded from the env layers and the process environment.
+// It is safe for concurrent use; Reload and Watch swap in a new Env rather than modifying
+// the current one.
 type AppEnv struct {
-	env Env
+	cfg     *appEnvConfig
+	baseDir string
+	layers  []Layer
+	// systemEnv is the process environment as it was before loading, so values set by a
+	// previous load do not win over the files on reload.
+	systemEnv Env
+
+	snapshot atomic.Pointer[envSnapshot]
+
+	mu          sync.Mutex
+	nextSubID   int
+	subscribers map[int]func(EnvDiff)
+}
+
+// envSnapshot is one loaded state of an AppEnv.
+type envSnapshot struct {
+	env     Env
+	sources map[string]string
+	// stamps hold a content hash of every resolved layer path as read, "" for missing
+	// files, so Watch can tell when they change.
+	stamps map[string]string
+	// secrets are the keys decrypted, resolved through a SecretProvider or declared secret.
+	secrets map[string]bool
+}
+
+// LoadError aggregates every problem found by LoadAppEnv.
+type LoadError struct {
+	Errors []error
+}
+
+func (e *LoadError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "load env failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *LoadError) Unwrap() []error {
+	return e.Errors
+}
+
+// AppEnvOption configures LoadAppEnv.
+type AppEnvOption func(*appEnvConfig)
+
+type appEnvConfig struct {
+	requiredKeys        []string
+	baseDir             string
+	defaultPath         string
+	currentEnvPattern   string
+	localOverride       bool
+	searchProjectRoot   bool
+	layers              []Layer
+	precedence          Precedence
+	noSystemMutation    bool
+	secretPatterns      []*regexp.Regexp
+	validators          []func(Env) error
+	strictInterpolation bool
+	secretProviders     map[string]SecretProvider
+	secretTimeout       time.Duration
+	decrypter           Decrypter
+	schema              EnvSchema
+}
+
+// WithRequiredKeys adds keys that must be present in addition to APP_NAME and CURRENT_ENV.
+func WithRequiredKeys(keys ...string) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.requiredKeys = append(cfg.requiredKeys, keys...)
+	}
+}
+
+// WithEnvValidator adds a check run on every load and reload once all required keys are
+// present, e.g. binding the Env into a config struct. Its error fails the load.
+func WithEnvValidator(validate func(Env) error) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.validators = append(cfg.validators, validate)
+	}
 }
 
 // NewAppEnv creates a new SioWSEnv environment.
 // It reads the default environment variables from a file,
-// merges them with environment-specific variables,
-// and sets the environment variables to the system.
-// It returns the merged environment.
-func NewAppEnv() *AppEnv {
-	appEnv := &AppEnv{}
-	appEnv.readEnvironment()
+// merges them with environment-specific variables and variables already set in the
+// process environment, and sets the environment variables to the system.
+// It returns the merged environment and panics if it cannot be loaded, see LoadAppEnv.
+func NewAppEnv(opts ...AppEnvOption) *AppEnv {
+	appEnv, err := LoadAppEnv(opts...)
+	if err != nil {
+		slog.Error(err.Error())
+		panic(err)
+	}
 
 	return appEnv
 }
 
+// LoadAppEnv is the error returning alternative to NewAppEnv. Rather than stopping at the
+// first problem it returns a *LoadError listing all of them: missing or malformed layer
+// files and every missing required key. See Layer and WithLayers for the files read.
+func LoadAppEnv(opts ...AppEnvOption) (*AppEnv, error) {
+	cfg := &appEnvConfig{
+		defaultPath:       DefaultFilePath,
+		currentEnvPattern: CurrentEnvFilePath,
+		secretTimeout:     DefaultSecretTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	layers := cfg.resolveLayers()
+	baseDir, err := cfg.resolveBaseDir(layers)
+	if err != nil {
+		return nil, &LoadError{Errors: []error{err}}
+	}
+
+	ae := &AppEnv{
+		cfg:         cfg,
+		baseDir:     baseDir,
+		layers:      layers,
+		systemEnv:   systemEnv(),
+		subscribers: make(map[int]func(EnvDiff)),
+	}
+
+	snapshot, err := ae.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.noSystemMutation {
+		if err := snapshot.env.setToSystem(snapshot.secrets); err != nil {
+			return nil, &LoadError{Errors: []error{err}}
+		}
+	}
+
+	ae.snapshot.Store(snapshot)
+
+	return ae, nil
+}
+
+// Env returns a copy of the current environment. Earlier versions returned the live map;
+// Env().Update now only changes the copy and is not seen by the AppEnv, Reload replaces the
+// environment instead. Use Value and LookupValue to read single keys without copying.
 func (ae *AppEnv) Env() Env {
-	return ae.env
+	return maps.Clone(ae.snapshot.Load().env)
 }
 
-// readEnvironment reads the environment configuration by merging the default environment file,
-// the current environment file, and setting the environment variables
-func (ae *AppEnv) readEnvironment() {
-	defaultEnvMap := readDefaultEnvFile()
-	defaultEnvMap.setToSystem()
+// Value returns the current value of key, or an empty string if it is not set.
+func (ae *AppEnv) Value(key string) string {
+	return ae.snapshot.Load().env.Value(key)
+}
+
+// LookupValue returns the current value of key and whether it is set to a non-empty value.
+func (ae *AppEnv) LookupValue(key string) (string, bool) {
+	return ae.snapshot.Load().env.LookupValue(key)
+}
+
+// load reads the layers and applies the process environment, returning a *LoadError
+// listing every problem.
+func (ae *AppEnv) load() (*envSnapshot, error) {
+	required := append([]string{EnvKeyAppName, EnvKeyCurrentEnv}, ae.cfg.requiredKeys...)
+	for _, key := range ae.cfg.schema.requiredKeys() {
+		if !slices.Contains(required, key) {
+			required = append(required, key)
+		}
+	}
+
+	var osEnv Env
+	if ae.cfg.precedence == OSFirst {
+		osEnv = ae.systemEnv
+	}
+
+	result := readLayers(ae.baseDir, ae.layers, osEnv)
+	mergedEnv, sources, errs := result.env, result.sources, result.errs
+	applySystemEnv(mergedEnv, sources, osEnv, append(required, ae.cfg.schema.keys()...))
+
+	// values from the process environment are final, only values from files are interpolated
+	for key, source := range sources {
+		if source == SourceOS {
+			result.literals[key] = true
+		}
+	}
+	errs = append(errs, interpolateEnv(mergedEnv, ae.systemEnv, result.literals, ae.cfg.strictInterpolation)...)
 
-	defaultEnvMap.ValuesPresent([]string{EnvKeyAppName, EnvKeyCurrentEnv})
+	decrypted, decryptErrs := decryptEnv(mergedEnv, ae.cfg.decrypter)
+	errs = append(errs, decryptErrs...)
 
-	currentEnvMap := readEnvironmentSpecificFile(defaultEnvMap.Value(EnvKeyCurrentEnv))
-	currentEnvMap.setToSystem()
+	ctx := context.Background()
+	if ae.cfg.secretTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ae.cfg.secretTimeout)
+		defer cancel()
+	}
+	secrets, secretErrs := resolveSecrets(ctx, mergedEnv, ae.cfg.secretProviders)
+	errs = append(errs, secretErrs...)
+	for key := range decrypted {
+		secrets[key] = true
+	}
+
+	for _, key := range ae.cfg.schema.ApplyDefaults(mergedEnv) {
+		sources[key] = SourceSchema
+	}
+	for _, key := range ae.cfg.schema.secretKeys() {
+		secrets[key] = true
+	}
+	secrets[EnvKeyEncryptionKeys] = true
+	secrets[EnvKeyEncryptionKeyID] = true
+	errs = append(errs, ae.cfg.schema.validate(mergedEnv, false, secrets)...)
+
+	errs = append(errs, mergedEnv.missingKeyErrors(required)...)
+	if len(errs) == 0 {
+		for _, validate := range ae.cfg.validators {
+			if err := validate(mergedEnv); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
 
-	mergedEnv := MergeEnvs(defaultEnvMap, currentEnvMap)
+	if len(errs) > 0 {
+		return nil, &LoadError{Errors: errs}
+	}
 
-	ae.env = mergedEnv
+	return &envSnapshot{env: mergedEnv, sources: sources, stamps: result.stamps, secrets: secrets}, nil
 }
 
-// readDefaultEnvFile reads the default environment file located at DefaultFilePath and returns its contents as a SioWSEnv map.
-// If the file cannot be read or an error occurs, it logs the error and panics with ErrNoEnvFile.
-func readDefaultEnvFile() Env {
-	defaultEnvFile, err := godotenv.Read(DefaultFilePath)
+// readEnvFile reads the dotenv or config file at path, see parserFor, wrapping parse errors
+// with ErrInvalidEnvFile. It also returns a content hash of the file, "" if it does not exist.
+func readEnvFile(path string) (dotenvFile, string, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error(fmt.Sprintf("default .env dotenv error: %v", err))
-		panic(ErrNoEnvFile)
+		return dotenvFile{}, "", err
 	}
 
-	return defaultEnvFile
+	stamp := contentStamp(content)
+
+	file, err := parserFor(path)(content)
+	if err != nil {
+		return dotenvFile{}, stamp, fmt.Errorf("%w %s: %w", ErrInvalidEnvFile, path, err)
+	}
+
+	return file, stamp, nil
 }
 
-// readEnvironmentSpecificFile reads the environment-specific file based on the given environment.
-// It takes an `env` string parameter indicating the environment.
-// It returns an instance of the `SioWSEnv` type that represents the environment-specific file.
-func readEnvironmentSpecificFile(env string) Env {
-	fileName := fmt.Sprintf(CurrentEnvFilePath, env)
+// LookupCurrentEnv returns the value of the `CURRENT_ENV` environment variable or an error wrapping ErrNoCurrentEnv.
+func LookupCurrentEnv() (string, error) {
+	currentEnv, ok := os.LookupEnv(EnvKeyCurrentEnv)
+	if !ok {
+		return "", fmt.Errorf("new environment: %w", ErrNoCurrentEnv)
+	}
 
-	defaultEnvFile, err := godotenv.Read(fileName)
-	if err != nil {
-		slog.Info("environment specific .env dotenv error: ", err)
+	return currentEnv, nil
+}
+
+// LookupAppName returns the value of the `APP_NAME` environment variable or an error wrapping ErrNoAppName.
+func LookupAppName() (string, error) {
+	appName, ok := os.LookupEnv(EnvKeyAppName)
+	if !ok {
+		return "", fmt.Errorf("new environment: %w", ErrNoAppName)
 	}
 
-	return defaultEnvFile
+	return appName, nil
 }
 
 // readCurrentEnv reads the value of the `CURRENT_ENV` environment variable.
 // If the environment variable is not found, it raises an error and panics.
 // It returns the value of the `CURRENT_ENV` environment variable.
 func readCurrentEnv() string {
-	appName, ok := os.LookupEnv(EnvKeyCurrentEnv)
-	if !ok {
-		err := fmt.Errorf("new environment: %w", ErrNoCurrentEnv)
-
+	currentEnv, err := LookupCurrentEnv()
+	if err != nil {
 		slog.Error(err.Error())
 		panic(err)
 	}
 
-	return appName
+	return currentEnv
 }
 
 // readAppName reads the value of the environment variable specified by AppNameKey,
@@ -157,10 +390,8 @@ func readCurrentEnv() string {
 // If the environment variable is not found, it logs an error and panics with an error message.
 // It returns the value of the environment variable as a string.
 func readAppName() string {
-	appName, ok := os.LookupEnv(EnvKeyAppName)
-	if !ok {
-		err := fmt.Errorf("new environment: %w", ErrNoAppName)
-
+	appName, err := LookupAppName()
+	if err != nil {
 		slog.Error(err.Error())
 		panic(err)
 	}
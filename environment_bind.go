@@ -0,0 +1,369 @@
+package siocore
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EnvTagKey       = "env"
+	EnvTagDefault   = "default"
+	EnvTagRequired  = "required"
+	EnvTagPrefix    = "envPrefix"
+	EnvTagSeparator = "envSeparator"
+	EnvTagSecret    = "secret"
+
+	DefaultEnvSeparator = ","
+	envMapKeySeparator  = ":"
+)
+
+var (
+	ErrEnvKeyNotFound     = errors.New("env key not found")
+	ErrInvalidEnvValue    = errors.New("invalid env value")
+	ErrInvalidBindTarget  = errors.New("env bind target must be a non-nil pointer to a struct")
+	ErrUnsupportedEnvType = errors.New("unsupported env field type")
+)
+
+// EnvKeyError describes a missing or malformed env key.
+type EnvKeyError struct {
+	Key string
+	// Value is the offending value. It is left empty for secrets so they are not logged.
+	Value string
+	Err   error
+}
+
+func (e *EnvKeyError) Error() string {
+	if e.Value == "" || errors.Is(e.Err, ErrEnvKeyNotFound) {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *EnvKeyError) Unwrap() error {
+	return e.Err
+}
+
+// BindError aggregates every missing or malformed key found by Env.Bind.
+type BindError struct {
+	Errors []*EnvKeyError
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "env bind failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap allows errors.Is(err, ErrEnvKeyNotFound) and errors.As(err, **EnvKeyError) on a BindError.
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// Bind populates the struct pointed to by v from the env according to its field tags, e.g.
+//
+//	type Config struct {
+//		Port     int               `env:"PORT" default:"8080"`
+//		Debug    bool              `env:"DEBUG"`
+//		Timeout  time.Duration     `env:"TIMEOUT" default:"5s"`
+//		Upstream *url.URL          `env:"UPSTREAM_URL" required:"true"`
+//		Hosts    []string          `env:"HOSTS" envSeparator:";"`
+//		Limits   map[string]int    `env:"LIMITS"` // LIMITS=read:10,write:5
+//		DB       struct {
+//			Host string `env:"HOST" required:"true"`
+//		} `envPrefix:"DB_"` // reads DB_HOST
+//	}
+//
+// Strings, bools, ints, uints, floats, time.Durations, url.URLs, types implementing
+// encoding.TextUnmarshaler and slices and maps of those are supported. Nested structs and
+// pointers to structs are bound recursively with their envPrefix prepended to their keys.
+// Empty values are treated as missing, like LookupValue.
+//
+// Every missing required key and malformed value is collected into a *BindError. Values of
+// fields tagged secret:"true" or keys matching the DefaultSecretPatterns are left out of it.
+// Any other
+// error means v or one of its fields cannot be bound.
+func (e Env) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+
+	bindErr := &BindError{}
+	if err := e.bindStruct(rv.Elem(), "", bindErr); err != nil {
+		return err
+	}
+
+	if len(bindErr.Errors) > 0 {
+		return bindErr
+	}
+
+	return nil
+}
+
+func (e Env) bindStruct(rv reflect.Value, prefix string, bindErr *BindError) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key, tagged := sf.Tag.Lookup(EnvTagKey)
+		if key == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if !tagged {
+			if !isNestedStruct(sf.Type) {
+				continue
+			}
+
+			if err := e.bindNested(fv, prefix+sf.Tag.Get(EnvTagPrefix), bindErr); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		key = prefix + key
+		raw, ok := e.LookupValue(key)
+		if !ok {
+			raw, ok = sf.Tag.Lookup(EnvTagDefault)
+		}
+
+		if !ok {
+			if required, _ := strconv.ParseBool(sf.Tag.Get(EnvTagRequired)); required {
+				bindErr.Errors = append(bindErr.Errors, &EnvKeyError{Key: key, Err: ErrEnvKeyNotFound})
+			}
+
+			continue
+		}
+
+		sep := sf.Tag.Get(EnvTagSeparator)
+		if sep == "" {
+			sep = DefaultEnvSeparator
+		}
+
+		if err := setEnvField(fv, raw, sep); err != nil {
+			if errors.Is(err, ErrUnsupportedEnvType) {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+
+			secret, _ := strconv.ParseBool(sf.Tag.Get(EnvTagSecret))
+			if secret || isSecretKey(key, DefaultSecretPatterns) {
+				// parse errors quote the value they failed on
+				err = fmt.Errorf("%w: not a valid %s", ErrInvalidEnvValue, fv.Type())
+				raw = ""
+			}
+
+			bindErr.Errors = append(bindErr.Errors, &EnvKeyError{Key: key, Value: raw, Err: err})
+		}
+	}
+
+	return nil
+}
+
+// bindNested binds a nested struct field, allocating it first if it is a nil pointer.
+func (e Env) bindNested(fv reflect.Value, prefix string, bindErr *BindError) error {
+	if fv.Kind() != reflect.Pointer {
+		return e.bindStruct(fv, prefix, bindErr)
+	}
+
+	if fv.IsNil() {
+		fv.Set(reflect.New(fv.Type().Elem()))
+	}
+
+	return e.bindStruct(fv.Elem(), prefix, bindErr)
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != urlType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setEnvField parses raw into fv. Slice elements and map entries are split on sep, map keys
+// and values on a colon.
+func setEnvField(fv reflect.Value, raw, sep string) error {
+	if fv.Kind() == reflect.Pointer {
+		value := reflect.New(fv.Type().Elem())
+		if err := setEnvField(value.Elem(), raw, sep); err != nil {
+			return err
+		}
+		fv.Set(value)
+
+		return nil
+	}
+
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		if err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+		}
+
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		parts := splitEnvList(raw, sep)
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvField(slice.Index(i), part, sep); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+
+		return nil
+	case reflect.Map:
+		m := reflect.MakeMap(fv.Type())
+		for _, entry := range splitEnvList(raw, sep) {
+			k, v, ok := strings.Cut(entry, envMapKeySeparator)
+			if !ok {
+				return fmt.Errorf("%w: map entry %q is not in key%svalue form", ErrInvalidEnvValue, entry, envMapKeySeparator)
+			}
+
+			key := reflect.New(fv.Type().Key()).Elem()
+			if err := setEnvField(key, strings.TrimSpace(k), sep); err != nil {
+				return err
+			}
+
+			value := reflect.New(fv.Type().Elem()).Elem()
+			if err := setEnvField(value, strings.TrimSpace(v), sep); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, value)
+		}
+		fv.Set(m)
+
+		return nil
+	default:
+		return setEnvScalar(fv, raw)
+	}
+}
+
+func setEnvScalar(fv reflect.Value, raw string) error {
+	if fv.Type() == durationType {
+		d, err := parseEnvDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+
+		return nil
+	}
+
+	if fv.Type() == urlType {
+		u, err := parseEnvURL(raw)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*u))
+
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := parseEnvBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedEnvType, fv.Type())
+	}
+
+	return nil
+}
+
+func parseEnvBool(raw string) (bool, error) {
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+	}
+
+	return b, nil
+}
+
+func parseEnvDuration(raw string) (time.Duration, error) {
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+	}
+
+	return d, nil
+}
+
+// parseEnvURL parses raw as an absolute URL.
+func parseEnvURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("%w: url %q is not absolute", ErrInvalidEnvValue, raw)
+	}
+
+	return u, nil
+}
+
+// splitEnvList splits raw on sep, trimming spaces and dropping empty elements.
+func splitEnvList(raw, sep string) []string {
+	parts := strings.Split(raw, sep)
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,167 @@
+package siocore
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bindDBConfig struct {
+	Host string `env:"HOST" required:"true"`
+	Port int    `env:"PORT" default:"5432"`
+}
+
+type bindConfig struct {
+	AppName   string            `env:"APP_NAME" required:"true"`
+	Port      int               `env:"PORT" default:"8080"`
+	Workers   uint8             `env:"WORKERS"`
+	Ratio     float64           `env:"RATIO"`
+	Debug     bool              `env:"DEBUG"`
+	Timeout   time.Duration     `env:"TIMEOUT" default:"5s"`
+	Upstream  *url.URL          `env:"UPSTREAM_URL"`
+	Callback  url.URL           `env:"CALLBACK_URL"`
+	Hosts     []string          `env:"HOSTS"`
+	Ports     []int             `env:"PORTS" envSeparator:";"`
+	Limits    map[string]int    `env:"LIMITS"`
+	Labels    map[string]string `env:"LABELS"`
+	StartedAt time.Time         `env:"STARTED_AT"`
+	MaxConns  *int              `env:"MAX_CONNS"`
+	DB        bindDBConfig      `envPrefix:"DB_"`
+	Cache     *bindDBConfig     `envPrefix:"CACHE_"`
+	Skipped   string            `env:"-"`
+	Untagged  string
+}
+
+func TestEnv_Bind(t *testing.T) {
+	env := Env{
+		"APP_NAME":     "orders",
+		"WORKERS":      "4",
+		"RATIO":        "0.75",
+		"DEBUG":        "true",
+		"TIMEOUT":      "1m30s",
+		"UPSTREAM_URL": "https://api.example.com/v1",
+		"CALLBACK_URL": "http://localhost:9000/cb",
+		"HOSTS":        "a.example.com, b.example.com,",
+		"PORTS":        "80;443",
+		"LIMITS":       "read:10, write:5",
+		"LABELS":       "team:core,tier:1",
+		"STARTED_AT":   "2024-06-15T12:00:00Z",
+		"MAX_CONNS":    "20",
+		"DB_HOST":      "db.internal",
+		"CACHE_HOST":   "cache.internal",
+		"CACHE_PORT":   "6379",
+		"Untagged":     "ignored",
+	}
+
+	var cfg bindConfig
+	assert.NoError(t, env.Bind(&cfg))
+
+	assert.Equal(t, "orders", cfg.AppName)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, uint8(4), cfg.Workers)
+	assert.Equal(t, 0.75, cfg.Ratio)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.Equal(t, "api.example.com", cfg.Upstream.Host)
+	assert.Equal(t, "/cb", cfg.Callback.Path)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, cfg.Hosts)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, map[string]int{"read": 10, "write": 5}, cfg.Limits)
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, cfg.Labels)
+	assert.Equal(t, time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC), cfg.StartedAt)
+	assert.Equal(t, 20, *cfg.MaxConns)
+	assert.Equal(t, bindDBConfig{Host: "db.internal", Port: 5432}, cfg.DB)
+	assert.Equal(t, &bindDBConfig{Host: "cache.internal", Port: 6379}, cfg.Cache)
+	assert.Empty(t, cfg.Untagged)
+}
+
+func TestEnv_Bind_AggregatesErrors(t *testing.T) {
+	env := Env{
+		"PORT":         "eighty",
+		"DEBUG":        "maybe",
+		"TIMEOUT":      "5 minutes",
+		"UPSTREAM_URL": "not a url",
+		"LIMITS":       "read=10",
+		"WORKERS":      "300",
+		"CACHE_HOST":   "cache.internal",
+	}
+
+	var cfg bindConfig
+	err := env.Bind(&cfg)
+
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("expected a bind error, got %v", err)
+	}
+
+	problems := make(map[string]error, len(bindErr.Errors))
+	for _, keyErr := range bindErr.Errors {
+		problems[keyErr.Key] = keyErr.Err
+	}
+
+	assert.Len(t, problems, 8)
+	assert.ErrorIs(t, problems["APP_NAME"], ErrEnvKeyNotFound)
+	assert.ErrorIs(t, problems["DB_HOST"], ErrEnvKeyNotFound)
+	for _, key := range []string{"PORT", "DEBUG", "TIMEOUT", "UPSTREAM_URL", "LIMITS", "WORKERS"} {
+		assert.ErrorIs(t, problems[key], ErrInvalidEnvValue, key)
+	}
+
+	assert.ErrorIs(t, err, ErrEnvKeyNotFound)
+	assert.Contains(t, err.Error(), "APP_NAME: env key not found")
+	assert.Contains(t, err.Error(), `PORT: "eighty": invalid env value`)
+}
+
+func TestEnv_Bind_SecretValues(t *testing.T) {
+	var cfg struct {
+		DBPassword int    `env:"DB_PASSWORD"`
+		PIN        int    `env:"PIN" secret:"true"`
+		Port       int    `env:"PORT"`
+		Name       string `env:"NAME"`
+	}
+
+	err := Env{"DB_PASSWORD": "hunter2", "PIN": "12ab", "PORT": "eighty", "NAME": "app"}.Bind(&cfg)
+
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("expected a bind error, got %v", err)
+	}
+
+	values := make(map[string]string, len(bindErr.Errors))
+	for _, keyErr := range bindErr.Errors {
+		values[keyErr.Key] = keyErr.Value
+		assert.ErrorIs(t, keyErr, ErrInvalidEnvValue, keyErr.Key)
+	}
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "", "PIN": "", "PORT": "eighty"}, values)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.NotContains(t, err.Error(), "12ab")
+}
+
+func TestEnv_Bind_InvalidDefault(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT" default:"http"`
+	}
+
+	err := Env{}.Bind(&cfg)
+
+	var keyErr *EnvKeyError
+	if assert.ErrorAs(t, err, &keyErr) {
+		assert.Equal(t, "PORT", keyErr.Key)
+		assert.Equal(t, "http", keyErr.Value)
+	}
+}
+
+func TestEnv_Bind_InvalidTarget(t *testing.T) {
+	var cfg bindConfig
+
+	assert.ErrorIs(t, Env{}.Bind(cfg), ErrInvalidBindTarget)
+	assert.ErrorIs(t, Env{}.Bind((*bindConfig)(nil)), ErrInvalidBindTarget)
+	assert.ErrorIs(t, Env{}.Bind(new(string)), ErrInvalidBindTarget)
+
+	var unsupported struct {
+		C chan int `env:"C"`
+	}
+	assert.ErrorIs(t, Env{"C": "1"}.Bind(&unsupported), ErrUnsupportedEnvType)
+}
@@ -0,0 +1,186 @@
+package siocore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrConflictingConfigKeys = errors.New("conflicting config keys")
+
+// envFileParser parses the content of a layer file.
+type envFileParser func(src []byte) (dotenvFile, error)
+
+// parserFor picks the parser for a layer file by its extension: .yaml, .yml, .json and
+// .toml files are config files, anything else is a dotenv file.
+func parserFor(path string) envFileParser {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAMLConfig
+	case ".json":
+		return parseJSONConfig
+	case ".toml":
+		return parseTOMLConfig
+	default:
+		return parseDotenv
+	}
+}
+
+func parseYAMLConfig(src []byte) (dotenvFile, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return dotenvFile{}, err
+	}
+
+	return flattenConfig(doc)
+}
+
+func parseJSONConfig(src []byte) (dotenvFile, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return dotenvFile{}, err
+	}
+
+	return flattenConfig(doc)
+}
+
+func parseTOMLConfig(src []byte) (dotenvFile, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(src, &doc); err != nil {
+		return dotenvFile{}, err
+	}
+
+	return flattenConfig(doc)
+}
+
+// flattenConfig maps a nested config document onto env keys: the path to every value is
+// joined with _ and upper cased, so
+//
+//	db:
+//	  host: localhost
+//	  replicas: [a, b]
+//	servers:
+//	  - name: api
+//
+// yields DB_HOST=localhost, DB_REPLICAS=a,b and SERVERS_0_NAME=api. Lists of scalars are
+// joined with DefaultEnvSeparator as Env.Bind and Env.StringSlice expect; other lists are
+// indexed. Paths mapping to the same key, e.g. db.host and DB_HOST, are an error.
+func flattenConfig(doc map[string]any) (dotenvFile, error) {
+	file := dotenvFile{values: Env{}, literals: make(map[string]bool)}
+	paths := make(map[string]string)
+
+	var errs []error
+	var flatten func(path []string, value any)
+	flatten = func(path []string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				flatten(append(path[:len(path):len(path)], key), v[key])
+			}
+
+			return
+		case map[any]any:
+			converted := make(map[string]any, len(v))
+			for key, item := range v {
+				converted[fmt.Sprint(key)] = item
+			}
+			flatten(path, converted)
+
+			return
+		case []map[string]any:
+			for i, item := range v {
+				flatten(append(path[:len(path):len(path)], strconv.Itoa(i)), item)
+			}
+
+			return
+		case []any:
+			if scalars, ok := scalarList(v); ok {
+				value = strings.Join(scalars, DefaultEnvSeparator)
+				break
+			}
+
+			for i, item := range v {
+				flatten(append(path[:len(path):len(path)], strconv.Itoa(i)), item)
+			}
+
+			return
+		}
+
+		key := configKey(path)
+		dotted := strings.Join(path, ".")
+		if other, ok := paths[key]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s and %s both map to %s", ErrConflictingConfigKeys, other, dotted, key))
+			return
+		}
+
+		paths[key] = dotted
+		file.values[key] = configScalar(value)
+	}
+
+	flatten(nil, doc)
+	if len(errs) > 0 {
+		return dotenvFile{}, errors.Join(errs...)
+	}
+
+	return file, nil
+}
+
+// configKey turns a config path into an env key, replacing anything but letters and digits with _.
+func configKey(path []string) string {
+	key := strings.ToUpper(strings.Join(path, "_"))
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, key)
+}
+
+func scalarList(list []any) ([]string, bool) {
+	scalars := make([]string, 0, len(list))
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]any, map[any]any, []any:
+			return nil, false
+		}
+
+		scalars = append(scalars, configScalar(item))
+	}
+
+	return scalars, true
+}
+
+func configScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
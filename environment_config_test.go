@@ -0,0 +1,131 @@
+package siocore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	expected := Env{
+		"DB_HOST":         "localhost",
+		"DB_PORT":         "5432",
+		"DB_RATIO":        "0.75",
+		"DB_TLS":          "true",
+		"DB_REPLICAS":     "a,b",
+		"DB_EMPTY":        "",
+		"SERVERS_0_NAME":  "api",
+		"SERVERS_1_NAME":  "worker",
+		"FEATURE_FLAGS_X": "on",
+	}
+
+	tests := []struct {
+		name string
+		path string
+		src  string
+	}{
+		{
+			name: "yaml",
+			path: "config.yaml",
+			src: `db:
+  host: localhost
+  port: 5432
+  ratio: 0.75
+  tls: true
+  replicas: [a, b]
+  empty: null
+servers:
+  - name: api
+  - name: worker
+feature-flags:
+  x: "on"
+`,
+		},
+		{
+			name: "json",
+			path: "config.json",
+			src: `{
+  "db": {"host": "localhost", "port": 5432, "ratio": 0.75, "tls": true, "replicas": ["a", "b"], "empty": null},
+  "servers": [{"name": "api"}, {"name": "worker"}],
+  "feature-flags": {"x": "on"}
+}`,
+		},
+		{
+			name: "toml",
+			path: "config.TOML",
+			src: `[db]
+host = "localhost"
+port = 5432
+ratio = 0.75
+tls = true
+replicas = ["a", "b"]
+empty = ""
+
+[[servers]]
+name = "api"
+
+[[servers]]
+name = "worker"
+
+[feature-flags]
+x = "on"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parserFor(tt.path)([]byte(tt.src))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, file.values)
+		})
+	}
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	_, err := parseYAMLConfig([]byte("db:\n  host: a\nDB:\n  HOST: b\n"))
+	assert.ErrorIs(t, err, ErrConflictingConfigKeys)
+	assert.ErrorContains(t, err, "DB.HOST and db.host both map to DB_HOST")
+
+	_, err = parseJSONConfig([]byte(`{"db": `))
+	assert.Error(t, err)
+
+	_, err = parseTOMLConfig([]byte("db = "))
+	assert.Error(t, err)
+}
+
+func TestLoadAppEnv_ConfigLayers(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "config/base.yaml"), "db:\n  host: yaml-db\n  port: 5432\nlevel: yaml\n")
+	writeLayerFile(t, filepath.Join(root, "config/dev.json"), `{"db": {"url": "postgres://${DB_HOST}:${DB_PORT}"}}`)
+	unsetEnvForTest(t, "DB_PORT", "DB_URL")
+
+	appEnv, err := LoadAppEnv(
+		WithBaseDir(root),
+		WithoutSystemMutation(),
+		WithLayers(
+			Layer{Name: LayerDefault, Path: DefaultFilePath},
+			Layer{Name: "config", Path: "config/base.yaml"},
+			Layer{Name: LayerCurrentEnv, Path: CurrentEnvFilePath},
+			Layer{Name: "config-env", Path: "config/%s.json"},
+		),
+	)
+	assert.NoError(t, err)
+
+	env := appEnv.Env()
+	assert.Equal(t, "dev-db", env.Value("DB_HOST"), "later dotenv layers override config layers")
+	assert.Equal(t, "current", env.Value("LEVEL"))
+	assert.Equal(t, "postgres://dev-db:5432", env.Value("DB_URL"), "config values are interpolated across layers")
+	assert.Equal(t, "config", appEnv.Source("DB_PORT"))
+
+	t.Run("invalid", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, "config/base.yaml"), "db: [\n")
+
+		_, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithLayers(
+			Layer{Name: LayerDefault, Path: DefaultFilePath},
+			Layer{Name: "config", Path: "config/base.yaml"},
+		))
+		assert.ErrorIs(t, err, ErrInvalidEnvFile)
+	})
+}
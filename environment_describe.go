@@ -0,0 +1,101 @@
+package siocore
+
+import (
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// MaskedValue replaces the values of secret keys in an EnvDescription.
+const MaskedValue = "******"
+
+// DefaultSecretPatterns match the names of keys whose values Describe masks.
+var DefaultSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)SECRET`),
+	regexp.MustCompile(`(?i)PASSW(OR)?D`),
+	regexp.MustCompile(`(?i)TOKEN`),
+	regexp.MustCompile(`(?i)(^|_)(API_?)?KEYS?($|_)`),
+	regexp.MustCompile(`(?i)CREDENTIAL`),
+	regexp.MustCompile(`(?i)PRIVATE`),
+	regexp.MustCompile(`(?i)(^|_)DSN($|_)`),
+}
+
+// WithSecretPatterns masks the values of keys matching any of patterns in Describe, in
+// addition to the DefaultSecretPatterns.
+func WithSecretPatterns(patterns ...*regexp.Regexp) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.secretPatterns = append(cfg.secretPatterns, patterns...)
+	}
+}
+
+// EnvEntry is a key of an AppEnv with its effective value and the layer or SourceOS it came from.
+type EnvEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret"`
+}
+
+// EnvDescription lists the keys of an AppEnv sorted by key.
+type EnvDescription []EnvEntry
+
+// Describe returns every key of the AppEnv with its value and source, suitable for a startup
+// log or a debug endpoint. Values of keys matching the secret patterns, declared secret by
+// the EnvSchema, decrypted or resolved through a SecretProvider are masked.
+func (ae *AppEnv) Describe() EnvDescription {
+	patterns := append(DefaultSecretPatterns[:len(DefaultSecretPatterns):len(DefaultSecretPatterns)], ae.cfg.secretPatterns...)
+	snapshot := ae.snapshot.Load()
+
+	description := make(EnvDescription, 0, len(snapshot.env))
+	for key, value := range snapshot.env {
+		entry := EnvEntry{Key: key, Value: value, Source: snapshot.sources[key]}
+		if snapshot.secrets[key] || isSecretKey(key, patterns) {
+			entry.Secret = true
+			entry.Value = MaskedValue
+		}
+
+		description = append(description, entry)
+	}
+
+	sort.Slice(description, func(i, j int) bool {
+		return description[i].Key < description[j].Key
+	})
+
+	return description
+}
+
+// String renders the description as an aligned table with a KEY, VALUE and SOURCE column.
+func (d EnvDescription) String() string {
+	var sb strings.Builder
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = tw.Write([]byte("KEY\tVALUE\tSOURCE\n"))
+	for _, entry := range d {
+		_, _ = tw.Write([]byte(entry.Key + "\t" + entry.Value + "\t" + entry.Source + "\n"))
+	}
+	_ = tw.Flush()
+
+	return sb.String()
+}
+
+// LogValue logs the description as a group of keys, each holding its value and source.
+func (d EnvDescription) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(d))
+	for _, entry := range d {
+		attrs = append(attrs, slog.Group(entry.Key, slog.String("value", entry.Value), slog.String("source", entry.Source)))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func isSecretKey(key string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package siocore
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppEnv_Describe(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "DB_PASSWORD=hunter2\nSTRIPE_API_KEY=sk_live\nMONKEY=banana\nINTERNAL_ID=42\n")
+	t.Setenv("AUTH_TOKEN", "abc")
+
+	appEnv, err := LoadAppEnv(
+		WithBaseDir(root),
+		WithLocalOverride(),
+		WithRequiredKeys("AUTH_TOKEN"),
+		WithSecretPatterns(regexp.MustCompile(`^INTERNAL_`)),
+		WithoutSystemMutation(),
+	)
+	assert.NoError(t, err)
+
+	description := appEnv.Describe()
+
+	entries := make(map[string]EnvEntry, len(description))
+	keys := make([]string, 0, len(description))
+	for _, entry := range description {
+		entries[entry.Key] = entry
+		keys = append(keys, entry.Key)
+	}
+
+	assert.IsIncreasing(t, keys)
+	assert.Equal(t, EnvEntry{Key: "DB_HOST", Value: "dev-db", Source: LayerCurrentEnv}, entries["DB_HOST"])
+	assert.Equal(t, EnvEntry{Key: "DB_PASSWORD", Value: MaskedValue, Source: LayerLocal, Secret: true}, entries["DB_PASSWORD"])
+	assert.Equal(t, EnvEntry{Key: "AUTH_TOKEN", Value: MaskedValue, Source: SourceOS, Secret: true}, entries["AUTH_TOKEN"])
+	assert.True(t, entries["STRIPE_API_KEY"].Secret)
+	assert.True(t, entries["INTERNAL_ID"].Secret)
+	assert.False(t, entries["MONKEY"].Secret)
+	assert.Equal(t, "banana", entries["MONKEY"].Value)
+}
+
+func TestAppEnv_Describe_EncryptionKeys(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "ENCRYPTION_KEYS=2024-06:c2VjcmV0\nENCRYPTION_KEY_ID=2024-06\nSIGNING_KEYS=k1\n")
+	unsetEnvForTest(t, EnvKeyEncryptionKeys, EnvKeyEncryptionKeyID, "SIGNING_KEYS")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithLocalOverride())
+	assert.NoError(t, err)
+
+	entries := make(map[string]EnvEntry)
+	for _, entry := range appEnv.Describe() {
+		entries[entry.Key] = entry
+	}
+
+	for _, key := range []string{EnvKeyEncryptionKeys, EnvKeyEncryptionKeyID, "SIGNING_KEYS"} {
+		assert.Equal(t, MaskedValue, entries[key].Value, key)
+		assert.True(t, entries[key].Secret, key)
+	}
+	for _, key := range []string{EnvKeyEncryptionKeys, EnvKeyEncryptionKeyID} {
+		_, set := os.LookupEnv(key)
+		assert.False(t, set, "%s is not exported", key)
+	}
+	assert.Equal(t, "2024-06:c2VjcmV0", appEnv.Value(EnvKeyEncryptionKeys))
+}
+
+func TestEnvDescription_String(t *testing.T) {
+	description := EnvDescription{
+		{Key: "APP_NAME", Value: "orders", Source: LayerDefault},
+		{Key: "DB_PASSWORD", Value: MaskedValue, Source: SourceOS, Secret: true},
+	}
+
+	lines := strings.Split(strings.TrimSpace(description.String()), "\n")
+
+	assert.Equal(t, []string{
+		"KEY          VALUE   SOURCE",
+		"APP_NAME     orders  default",
+		"DB_PASSWORD  ******  os",
+	}, lines)
+}
+
+func TestEnvDescription_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Info("config", "env", EnvDescription{{Key: "PORT", Value: "8080", Source: LayerDefault}})
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]any{"PORT": map[string]any{"value": "8080", "source": "default"}}, entry["env"])
+}
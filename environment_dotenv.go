@@ -0,0 +1,211 @@
+package siocore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// dotenvFile is a parsed dotenv file. Literal keys had single quoted values, which are not
+// interpolated.
+type dotenvFile struct {
+	values   Env
+	literals map[string]bool
+}
+
+// parseDotenv parses dotenv content without expanding variables, which is left to
+// interpolate so references can span layers. godotenv.Parse cannot be used for this: it
+// replaces references to keys not defined earlier in the same file with "", consumes \$
+// escapes and does not report which values were single quoted. It accepts the syntax
+// godotenv does:
+//
+//	# comment
+//	export KEY=value # inline comment
+//	KEY: value
+//	KEY='single quoted, taken literally'
+//	KEY="double quoted, with \n escapes
+//	and line breaks"
+//
+// Unquoted and double quoted values are interpolated like godotenv expands them: $KEY and
+// ${KEY} are references, \$ is a literal $ and any other $, e.g. in pa$$word, is kept.
+func parseDotenv(src []byte) (dotenvFile, error) {
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	p := &dotenvParser{src: src}
+	file := dotenvFile{values: Env{}, literals: make(map[string]bool)}
+
+	for {
+		p.skipBlankAndComments()
+		if p.eof() {
+			return file, nil
+		}
+
+		key, err := p.key()
+		if err != nil {
+			return dotenvFile{}, err
+		}
+
+		value, literal, err := p.value()
+		if err != nil {
+			return dotenvFile{}, err
+		}
+
+		file.values[key] = value
+		if literal {
+			file.literals[key] = true
+		} else {
+			delete(file.literals, key)
+		}
+	}
+}
+
+type dotenvParser struct {
+	src []byte
+	pos int
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	line := bytes.Count(p.src[:min(p.pos, len(p.src))], []byte("\n")) + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipBlankAndComments() {
+	for !p.eof() {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) key() (string, error) {
+	if rest := p.src[p.pos:]; bytes.HasPrefix(rest, []byte("export ")) || bytes.HasPrefix(rest, []byte("export\t")) {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.pos
+	for !p.eof() && isDotenvKeyChar(p.src[p.pos]) {
+		p.pos++
+	}
+	key := string(p.src[start:p.pos])
+
+	p.skipSpaces()
+	if key == "" || p.eof() || (p.src[p.pos] != '=' && p.src[p.pos] != ':') {
+		end := bytes.IndexByte(p.src[start:], '\n')
+		if end == -1 {
+			end = len(p.src) - start
+		}
+
+		return "", p.errorf("expected KEY=value near %q", p.src[start:start+end])
+	}
+	p.pos++
+
+	return key, nil
+}
+
+func (p *dotenvParser) value() (string, bool, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return "", false, nil
+	}
+
+	switch quote := p.src[p.pos]; quote {
+	case '\'', '"':
+		value, err := p.quoted(quote)
+		if err != nil {
+			return "", false, err
+		}
+
+		p.skipSpaces()
+		if !p.eof() && p.src[p.pos] == '#' {
+			p.skipLine()
+		}
+		if !p.eof() && p.src[p.pos] != '\n' {
+			return "", false, p.errorf("unexpected %q after quoted value", p.src[p.pos])
+		}
+
+		return value, quote == '\'', nil
+	default:
+		start := p.pos
+		p.skipLine()
+		line := string(p.src[start:p.pos])
+
+		// like godotenv, the last # preceded by whitespace starts an inline comment
+		for i := len(line) - 1; i > 0; i-- {
+			if line[i] == '#' && (line[i-1] == ' ' || line[i-1] == '\t') {
+				line = line[:i]
+				break
+			}
+		}
+
+		return strings.TrimSpace(line), false, nil
+	}
+}
+
+// quoted reads a value enclosed in quote, which may span lines. Double quoted values
+// support the \n, \r, \t, \" and \\ escapes. \$ is kept for interpolate, which reads it as
+// a literal $.
+func (p *dotenvParser) quoted(quote byte) (string, error) {
+	start := p.pos
+	p.pos++
+
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && !p.eof() && p.src[p.pos] == quote:
+			sb.WriteByte(quote)
+			p.pos++
+		case c == '\\' && quote == '"' && !p.eof():
+			next := p.src[p.pos]
+			p.pos++
+			switch next {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '\\':
+				sb.WriteByte('\\')
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(next)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	p.pos = start
+	return "", p.errorf("unterminated quoted value")
+}
+
+func isDotenvKeyChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
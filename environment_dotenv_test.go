@@ -0,0 +1,103 @@
+package siocore
+
+import (
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDotenv(t *testing.T) {
+	src := "# comment\n" +
+		"PLAIN=value\n" +
+		"  SPACED = padded value   \n" +
+		"export EXPORTED=yes\n" +
+		"YAML: style\n" +
+		"INLINE=value # comment\n" +
+		"HASH=abc#def\n" +
+		"EMPTY=\n" +
+		"SINGLE='${NOT_EXPANDED} \\n'\n" +
+		"DOUBLE=\"line1\\nline2 \\\"quoted\\\" \\$HOME\" # comment\n" +
+		"MULTI=\"first\nsecond\"\r\n" +
+		"REF=${PLAIN}\n" +
+		"dotted.key=1\n" +
+		"LAST=no newline"
+
+	file, err := parseDotenv([]byte(src))
+	assert.NoError(t, err)
+
+	assert.Equal(t, Env{
+		"PLAIN":      "value",
+		"SPACED":     "padded value",
+		"EXPORTED":   "yes",
+		"YAML":       "style",
+		"INLINE":     "value",
+		"HASH":       "abc#def",
+		"EMPTY":      "",
+		"SINGLE":     "${NOT_EXPANDED} \\n",
+		"DOUBLE":     "line1\nline2 \"quoted\" \\$HOME",
+		"MULTI":      "first\nsecond",
+		"REF":        "${PLAIN}",
+		"dotted.key": "1",
+		"LAST":       "no newline",
+	}, file.values)
+	assert.Equal(t, map[string]bool{"SINGLE": true}, file.literals)
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{name: "missing separator", src: "A=1\nthis is bad\n", expected: "line 2: expected KEY=value"},
+		{name: "unterminated quote", src: "A=\"open\n", expected: "line 1: unterminated quoted value"},
+		{name: "text after quote", src: "A='x' y\n", expected: "line 1: unexpected"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseDotenv([]byte(tc.src))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+// TestParseDotenv_Godotenv checks that parsing and interpolating a file yields the values
+// godotenv loads from it.
+func TestParseDotenv_Godotenv(t *testing.T) {
+	tt := []struct {
+		name string
+		src  string
+	}{
+		{name: "plain", src: "A=value\n"},
+		{name: "export", src: "export A=value\nexport\tB=tabbed\n"},
+		{name: "yaml style", src: "A: value\n"},
+		{name: "inline comment", src: "A=value # comment\nB=abc#def\nC=x # y # z\n"},
+		{name: "comment after quotes", src: "A=\"quoted # kept\" # comment\nB='single' # comment\n"},
+		{name: "unbraced reference", src: "HOST=db\nA=$HOST/x\nB=\"$HOST:5432\"\n"},
+		{name: "braced reference", src: "HOST=db\nA=${HOST}/x\nB=\"${HOST}\"\n"},
+		{name: "undefined reference", src: "A=[$NOPE]\nB=[${NOPE}]\n"},
+		{name: "double dollar", src: "A=pa$$word\nB=\"pa$$word\"\nC=cost$\n"},
+		{name: "lower case name", src: "A=$home\n"},
+		{name: "escaped dollar", src: "HOST=db\nA=\\$HOST\nB=\"\\$HOST\"\n"},
+		{name: "single quotes are literal", src: "HOST=db\nA='$HOST ${HOST} \\n'\n"},
+		{name: "double quote escapes", src: "A=\"line1\\nline2 \\\"quoted\\\" text\"\n"},
+		{name: "multiline", src: "A=\"first\nsecond\"\r\nB=after\n"},
+		{name: "empty", src: "A=\nB=\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := godotenv.Unmarshal(tc.src)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			file, err := parseDotenv([]byte(tc.src))
+			assert.NoError(t, err)
+			assert.Empty(t, interpolateEnv(file.values, nil, file.literals, false))
+			assert.Equal(t, Env(expected), file.values)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package siocore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	encryptedValuePrefix = "ENC("
+	encryptedValueSuffix = ")"
+)
+
+var (
+	ErrEnvDecryption = errors.New("unable to decrypt env value")
+	ErrNoDecrypter   = errors.New("encrypted env value but no decrypter configured")
+)
+
+// Decrypter decrypts the ciphertext of ENC(...) env values, e.g. a crypto.Encryptor.
+type Decrypter interface {
+	Decrypt(ciphertext string) (string, error)
+}
+
+// WithDecrypter decrypts env values of the form ENC(ciphertext) with d once the layers are
+// merged and interpolated. Decrypted keys are masked by Describe. Without a Decrypter,
+// encrypted values fail the load.
+func WithDecrypter(d Decrypter) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.decrypter = d
+	}
+}
+
+// EncryptedValue wraps ciphertext as an ENC(...) env value.
+func EncryptedValue(ciphertext string) string {
+	return encryptedValuePrefix + ciphertext + encryptedValueSuffix
+}
+
+// IsEncryptedValue reports whether value is of the form ENC(ciphertext).
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix) && strings.HasSuffix(value, encryptedValueSuffix)
+}
+
+// decryptEnv replaces the ENC(...) values of env with their plaintext, returning the
+// decrypted keys and an *EnvKeyError for every value that cannot be decrypted. The ciphertext
+// is left out of the errors.
+func decryptEnv(env Env, d Decrypter) (map[string]bool, []error) {
+	decrypted := make(map[string]bool)
+	var errs []error
+
+	for key, value := range env {
+		if !IsEncryptedValue(value) {
+			continue
+		}
+
+		if d == nil {
+			errs = append(errs, &EnvKeyError{Key: key, Err: ErrNoDecrypter})
+			continue
+		}
+
+		ciphertext := strings.TrimSuffix(strings.TrimPrefix(value, encryptedValuePrefix), encryptedValueSuffix)
+		plaintext, err := d.Decrypt(ciphertext)
+		if err != nil {
+			errs = append(errs, &EnvKeyError{Key: key, Err: fmt.Errorf("%w: %w", ErrEnvDecryption, err)})
+			continue
+		}
+
+		env[key] = plaintext
+		decrypted[key] = true
+	}
+
+	return decrypted, errs
+}
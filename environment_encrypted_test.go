@@ -0,0 +1,84 @@
+package siocore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reverseDecrypter "decrypts" by reversing the ciphertext and rejects anything starting with "bad".
+type reverseDecrypter struct{}
+
+func (reverseDecrypter) Decrypt(ciphertext string) (string, error) {
+	if strings.HasPrefix(ciphertext, "bad") {
+		return "", errors.New("invalid ciphertext")
+	}
+
+	runes := []rune(ciphertext)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes), nil
+}
+
+func TestIsEncryptedValue(t *testing.T) {
+	assert.True(t, IsEncryptedValue(EncryptedValue("abc")))
+	assert.True(t, IsEncryptedValue("ENC()"))
+	assert.False(t, IsEncryptedValue("ENC(abc"))
+	assert.False(t, IsEncryptedValue("enc(abc)"))
+	assert.False(t, IsEncryptedValue("plain"))
+}
+
+func TestLoadAppEnv_Decrypter(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=ENC(2retnuh)\nPLAIN=value\n")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithDecrypter(reverseDecrypter{}))
+	assert.NoError(t, err)
+
+	env := appEnv.Env()
+	assert.Equal(t, "hunter2", env.Value("DB_PASS"))
+	assert.Equal(t, "value", env.Value("PLAIN"))
+
+	for _, entry := range appEnv.Describe() {
+		if entry.Key == "DB_PASS" {
+			assert.Equal(t, MaskedValue, entry.Value)
+		}
+	}
+
+	t.Run("not set in the process environment", func(t *testing.T) {
+		unsetEnvForTest(t, "DB_PASS", "PLAIN")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithDecrypter(reverseDecrypter{}))
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", appEnv.Env().Value("DB_PASS"))
+		assert.Equal(t, "value", os.Getenv("PLAIN"))
+		_, set := os.LookupEnv("DB_PASS")
+		assert.False(t, set, "decrypted values are not exported")
+
+		writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=ENC(3retnuh)\nPLAIN=changed\n")
+		assert.NoError(t, appEnv.Reload())
+		assert.Equal(t, "hunter3", appEnv.Env().Value("DB_PASS"))
+		assert.Equal(t, "changed", os.Getenv("PLAIN"))
+		_, set = os.LookupEnv("DB_PASS")
+		assert.False(t, set, "decrypted values are not exported on reload")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=ENC(bad)\n")
+
+		_, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithDecrypter(reverseDecrypter{}))
+		assert.ErrorIs(t, err, ErrEnvDecryption)
+		assert.ErrorContains(t, err, "DB_PASS")
+		assert.NotContains(t, err.Error(), "ENC(bad)", "the ciphertext is left out")
+
+		_, err = LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+		assert.ErrorIs(t, err, ErrNoDecrypter)
+		assert.NotContains(t, err.Error(), "ENC(bad)")
+	})
+}
@@ -0,0 +1,207 @@
+package siocore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEnvReferenceCycle     = errors.New("env reference cycle")
+	ErrUndefinedEnvReference = errors.New("undefined env reference")
+)
+
+// WithStrictInterpolation makes references to undefined keys without a default fail the
+// load instead of expanding to an empty string.
+func WithStrictInterpolation() AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.strictInterpolation = true
+	}
+}
+
+// interpolator expands references in the values of an Env once all layers are merged, so
+// a value may refer to keys of any layer:
+//
+//	DB_HOST=db.internal
+//	DB_URL=postgres://${DB_HOST}:${DB_PORT:-5432}/app
+//
+// ${KEY} and $KEY expand to the value of KEY, looked up in the Env and then the process
+// environment. ${KEY:-default} expands to default when KEY is unset or empty; default may
+// itself hold references. As in godotenv, unbraced names are upper case letters, digits and
+// _, \$ is a literal $ and a $ starting no reference, e.g. in $$ or $5, is kept as is.
+type interpolator struct {
+	env      Env
+	fallback Env
+	literals map[string]bool
+	strict   bool
+
+	resolved  map[string]bool
+	resolving []string
+	failed    map[string]bool
+	errs      []error
+}
+
+// interpolateEnv expands the references in the values of env in place. Keys in literals are
+// left as they are. Every cycle and, when strict, every undefined reference is returned.
+func interpolateEnv(env, fallback Env, literals map[string]bool, strict bool) []error {
+	in := &interpolator{
+		env:      env,
+		fallback: fallback,
+		literals: literals,
+		strict:   strict,
+		resolved: make(map[string]bool),
+		failed:   make(map[string]bool),
+	}
+
+	for key := range env {
+		in.resolve(key)
+	}
+
+	return in.errs
+}
+
+func (in *interpolator) resolve(key string) (string, bool) {
+	if in.resolved[key] || in.literals[key] {
+		return in.env[key], true
+	}
+	if in.failed[key] {
+		return "", false
+	}
+
+	for i, resolving := range in.resolving {
+		if resolving == key {
+			cycle := append(in.resolving[i:len(in.resolving):len(in.resolving)], key)
+			// the raw value is left out as it may embed a secret next to the reference
+			in.errs = append(in.errs, &EnvKeyError{
+				Key: key,
+				Err: fmt.Errorf("%w: %s", ErrEnvReferenceCycle, strings.Join(cycle, " -> ")),
+			})
+			for _, k := range cycle {
+				in.failed[k] = true
+			}
+
+			return "", false
+		}
+	}
+
+	in.resolving = append(in.resolving, key)
+	value, ok := in.expand(key, in.env[key])
+	in.resolving = in.resolving[:len(in.resolving)-1]
+
+	if !ok || in.failed[key] {
+		in.failed[key] = true
+		return "", false
+	}
+
+	in.env[key] = value
+	in.resolved[key] = true
+
+	return value, true
+}
+
+// expand replaces the references in value, which belongs to key.
+func (in *interpolator) expand(key, value string) (string, bool) {
+	if !strings.Contains(value, "$") {
+		return value, true
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			sb.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 >= len(value) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		switch next := value[i+1]; {
+		case next == '{':
+			end := matchingBrace(value, i+2)
+			if end == -1 {
+				sb.WriteByte(c)
+				continue
+			}
+
+			expanded, ok := in.reference(key, value[i+2:end])
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(expanded)
+			i = end
+		case next == '_' || (next >= 'A' && next <= 'Z'):
+			end := i + 2
+			for end < len(value) && isReferenceNameChar(value[end]) {
+				end++
+			}
+
+			expanded, ok := in.reference(key, value[i+1:end])
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(expanded)
+			i = end - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), true
+}
+
+// reference expands the body of a ${...} reference, or the name of a $KEY one, found in the
+// value of key.
+func (in *interpolator) reference(key, body string) (string, bool) {
+	name, def, hasDefault := strings.Cut(body, ":-")
+
+	var value string
+	if _, ok := in.env[name]; ok {
+		resolved, ok := in.resolve(name)
+		if !ok {
+			return "", false
+		}
+		value = resolved
+	} else if fallback, ok := in.fallback[name]; ok {
+		value = fallback
+	} else if !hasDefault && in.strict {
+		in.errs = append(in.errs, &EnvKeyError{
+			Key: key,
+			Err: fmt.Errorf("%w: %s", ErrUndefinedEnvReference, name),
+		})
+
+		return "", false
+	}
+
+	if value == "" && hasDefault {
+		return in.expand(key, def)
+	}
+
+	return value, true
+}
+
+func isReferenceNameChar(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// matchingBrace returns the index of the } closing a reference whose body starts at start,
+// skipping nested references, or -1 if there is none.
+func matchingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
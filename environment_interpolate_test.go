@@ -0,0 +1,94 @@
+package siocore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	env := Env{
+		"HOST":     "db.internal",
+		"PORT":     "",
+		"URL":      "postgres://${HOST}:${PORT:-5432}/${DB_NAME:-${APP}}",
+		"APP":      "orders",
+		"CHAIN":    "${URL}?sslmode=disable",
+		"ESCAPED":  "\\${HOST} costs \\$5",
+		"BARE":     "$HOST:$PORT/$APP_",
+		"DOLLARS":  "pa$$word $HOST$",
+		"LITERAL":  "${HOST}",
+		"FROM_OS":  "${SYSTEM_ONLY}",
+		"UNKNOWN":  "[${NOPE}]",
+		"DANGLING": "${HOST",
+		"PRICE":    "$5",
+	}
+
+	errs := interpolateEnv(env, Env{"SYSTEM_ONLY": "from-os"}, map[string]bool{"LITERAL": true}, false)
+	assert.Empty(t, errs)
+
+	assert.Equal(t, "postgres://db.internal:5432/orders", env["URL"])
+	assert.Equal(t, "postgres://db.internal:5432/orders?sslmode=disable", env["CHAIN"])
+	assert.Equal(t, "${HOST} costs $5", env["ESCAPED"])
+	assert.Equal(t, "db.internal:/", env["BARE"])
+	assert.Equal(t, "pa$$word db.internal$", env["DOLLARS"])
+	assert.Equal(t, "${HOST}", env["LITERAL"])
+	assert.Equal(t, "from-os", env["FROM_OS"])
+	assert.Equal(t, "[]", env["UNKNOWN"])
+	assert.Equal(t, "${HOST", env["DANGLING"])
+	assert.Equal(t, "$5", env["PRICE"])
+}
+
+func TestInterpolateEnv_Errors(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		env := Env{"A": "${B}", "B": "x${C}", "C": "${A}", "D": "${A}", "OK": "fine"}
+
+		errs := interpolateEnv(env, nil, nil, false)
+		if assert.Len(t, errs, 1) {
+			assert.ErrorIs(t, errs[0], ErrEnvReferenceCycle)
+			assert.NotContains(t, errs[0].Error(), "x${C}")
+		}
+		assert.Equal(t, "fine", env["OK"])
+	})
+
+	t.Run("self reference", func(t *testing.T) {
+		errs := interpolateEnv(Env{"A": "${A}"}, nil, nil, false)
+		if assert.Len(t, errs, 1) {
+			assert.ErrorContains(t, errs[0], "A -> A")
+		}
+	})
+
+	t.Run("strict undefined", func(t *testing.T) {
+		env := Env{"DB_PASSWORD": "hunter2${NOPE}", "B": "${NOPE:-default}"}
+
+		errs := interpolateEnv(env, nil, nil, true)
+		if assert.Len(t, errs, 1) {
+			assert.ErrorIs(t, errs[0], ErrUndefinedEnvReference)
+			assert.EqualError(t, errs[0], "DB_PASSWORD: undefined env reference: NOPE", "the value is left out")
+		}
+		assert.Equal(t, "default", env["B"])
+	})
+}
+
+func TestLoadAppEnv_Interpolation(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_HOST=dev-db\nDB_URL=postgres://${DB_HOST}:${DB_PORT:-5432}/${APP_NAME}\nRAW='${DB_HOST}'\n")
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "DB_HOST=localhost\n")
+	t.Setenv("INJECTED", "${DB_HOST}")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithLocalOverride(), WithRequiredKeys("INJECTED"), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	env := appEnv.Env()
+	assert.Equal(t, "postgres://localhost:5432/layers", env.Value("DB_URL"), "references resolve against the merged layers")
+	assert.Equal(t, "${DB_HOST}", env.Value("RAW"))
+	assert.Equal(t, "${DB_HOST}", env.Value("INJECTED"), "process environment values are not expanded")
+
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "DB_HOST=${DB_URL}\n")
+	_, err = LoadAppEnv(WithBaseDir(root), WithLocalOverride(), WithoutSystemMutation())
+	assert.ErrorIs(t, err, ErrEnvReferenceCycle)
+
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "DB_HOST=${UNDEFINED_HOST}\n")
+	_, err = LoadAppEnv(WithBaseDir(root), WithLocalOverride(), WithoutSystemMutation(), WithStrictInterpolation())
+	assert.ErrorIs(t, err, ErrUndefinedEnvReference)
+}
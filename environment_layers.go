@@ -0,0 +1,204 @@
+package siocore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	LocalFilePath = "env/.env.local"
+
+	LayerDefault    = "default"
+	LayerCurrentEnv = "current"
+	LayerLocal      = "local"
+
+	goModFile = "go.mod"
+)
+
+// Layer is a dotenv file read by LoadAppEnv, or a YAML, JSON or TOML config file whose
+// nested keys are flattened into env keys, e.g. db.host to DB_HOST. A %s in Path is replaced
+// with the CURRENT_ENV value found in the layers before it, or in the process environment
+// with OSFirst, and relative paths are resolved against the base directory. A missing
+// Optional layer is skipped, any other missing layer is reported as ErrNoEnvFile.
+type Layer struct {
+	Name     string
+	Path     string
+	Optional bool
+}
+
+// WithBaseDir resolves relative layer paths against dir instead of the working directory.
+func WithBaseDir(dir string) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.baseDir = dir
+	}
+}
+
+// WithFilePatterns replaces DefaultFilePath and CurrentEnvFilePath as the paths of the
+// default layers. currentEnvPattern must contain a %s for the CURRENT_ENV value.
+func WithFilePatterns(defaultPath, currentEnvPattern string) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.defaultPath = defaultPath
+		cfg.currentEnvPattern = currentEnvPattern
+	}
+}
+
+// WithLocalOverride adds an optional LocalFilePath layer after all other layers, meant for
+// untracked developer overrides.
+func WithLocalOverride() AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.localOverride = true
+	}
+}
+
+// WithProjectRootSearch walks up from the base directory to the first directory holding
+// the first layer's file, stopping at the directory holding go.mod. This lets tests running
+// in subpackages find the env files of the module root.
+func WithProjectRootSearch() AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.searchProjectRoot = true
+	}
+}
+
+// WithLayers replaces the default layers with layers, read in order.
+func WithLayers(layers ...Layer) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.layers = layers
+	}
+}
+
+// resolveLayers returns the layers to read in order. Unless replaced by WithLayers they are:
+//
+//  1. default: DefaultFilePath, required
+//  2. current: CurrentEnvFilePath for the CURRENT_ENV value, optional
+//  3. local:   LocalFilePath, optional, only added by WithLocalOverride
+//
+// Values of later layers override values of earlier ones.
+func (cfg *appEnvConfig) resolveLayers() []Layer {
+	layers := cfg.layers
+	if layers == nil {
+		layers = []Layer{
+			{Name: LayerDefault, Path: cfg.defaultPath},
+			{Name: LayerCurrentEnv, Path: cfg.currentEnvPattern, Optional: true},
+		}
+	}
+
+	if cfg.localOverride {
+		layers = append(layers[:len(layers):len(layers)], Layer{Name: LayerLocal, Path: LocalFilePath, Optional: true})
+	}
+
+	return layers
+}
+
+// resolveBaseDir returns the directory relative layer paths are resolved against.
+func (cfg *appEnvConfig) resolveBaseDir(layers []Layer) (string, error) {
+	baseDir := cfg.baseDir
+	if baseDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("resolve base dir: %w", err)
+		}
+		baseDir = wd
+	}
+
+	if !cfg.searchProjectRoot || len(layers) == 0 {
+		return baseDir, nil
+	}
+
+	return findProjectRoot(baseDir, layers[0].Path)
+}
+
+// findProjectRoot walks up from dir to the first directory containing marker or go.mod.
+// dir itself is returned if neither is found.
+func findProjectRoot(dir, marker string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolve base dir: %w", err)
+	}
+
+	for current := dir; ; {
+		if fileExists(filepath.Join(current, marker)) || fileExists(filepath.Join(current, goModFile)) {
+			return current, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir, nil
+		}
+		current = parent
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// layerResult is the outcome of readLayers.
+type layerResult struct {
+	env Env
+	// sources hold the name of the layer each key came from.
+	sources map[string]string
+	// literals are the keys whose effective value is not interpolated.
+	literals map[string]bool
+	// stamps hold the content hash of every resolved layer path, see readEnvFile.
+	stamps map[string]string
+	errs   []error
+}
+
+// readLayers reads and merges layers in order, collecting every problem instead of stopping
+// at the first one. A CURRENT_ENV in osEnv takes precedence over the layers when resolving
+// %s paths.
+func readLayers(baseDir string, layers []Layer, osEnv Env) layerResult {
+	result := layerResult{
+		env:      Env{},
+		sources:  make(map[string]string),
+		literals: make(map[string]bool),
+		stamps:   make(map[string]string),
+	}
+
+	for _, layer := range layers {
+		path := layer.Path
+		if strings.Contains(path, "%s") {
+			currentEnv, ok := osEnv.LookupValue(EnvKeyCurrentEnv)
+			if !ok {
+				currentEnv, ok = result.env.LookupValue(EnvKeyCurrentEnv)
+			}
+			if !ok {
+				if !layer.Optional {
+					result.errs = append(result.errs, fmt.Errorf("layer %s: %w", layer.Name, ErrNoCurrentEnv))
+				}
+				continue
+			}
+			path = fmt.Sprintf(path, currentEnv)
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+
+		file, stamp, err := readEnvFile(path)
+		result.stamps[path] = stamp
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && layer.Optional:
+			slog.Info("optional env layer not found", "layer", layer.Name, "path", path)
+		case errors.Is(err, fs.ErrNotExist):
+			result.errs = append(result.errs, fmt.Errorf("%w: layer %s: %w", ErrNoEnvFile, layer.Name, err))
+		case err != nil:
+			result.errs = append(result.errs, fmt.Errorf("layer %s: %w", layer.Name, err))
+		default:
+			for key, value := range file.values {
+				result.env[key] = value
+				result.sources[key] = layer.Name
+				result.literals[key] = file.literals[key]
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,109 @@
+package siocore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeLayerFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func layeredProject(t *testing.T) string {
+	t.Helper()
+
+	unsetEnvForTest(t, EnvKeyAppName, EnvKeyCurrentEnv, EnvKeyPort, "LEVEL", "DB_HOST", "DB_PASSWORD")
+
+	root := t.TempDir()
+	writeLayerFile(t, filepath.Join(root, goModFile), "module example.com/app\n")
+	writeLayerFile(t, filepath.Join(root, DefaultFilePath), "APP_NAME=layers\nCURRENT_ENV=dev\nLEVEL=default\nPORT=8080\n")
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LEVEL=current\nDB_HOST=dev-db\n")
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "LEVEL=local\n")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "internal", "store"), 0o755))
+
+	return root
+}
+
+func TestLoadAppEnv_BaseDir(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root))
+	assert.NoError(t, err)
+	assert.Equal(t, "current", appEnv.Env().Value("LEVEL"))
+	assert.Equal(t, "dev-db", appEnv.Env().Value("DB_HOST"))
+	assert.Equal(t, "8080", appEnv.Env().Value("PORT"))
+}
+
+func TestLoadAppEnv_LocalOverride(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithLocalOverride())
+	assert.NoError(t, err)
+	assert.Equal(t, "local", appEnv.Env().Value("LEVEL"))
+	assert.Equal(t, "dev-db", appEnv.Env().Value("DB_HOST"))
+}
+
+func TestLoadAppEnv_ProjectRootSearch(t *testing.T) {
+	root := layeredProject(t)
+	subpackage := filepath.Join(root, "internal", "store")
+
+	_, err := LoadAppEnv(WithBaseDir(subpackage))
+	assert.ErrorIs(t, err, ErrNoEnvFile)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(subpackage), WithProjectRootSearch())
+	assert.NoError(t, err)
+	assert.Equal(t, "layers", appEnv.Env().Value(EnvKeyAppName))
+}
+
+func TestLoadAppEnv_FilePatterns(t *testing.T) {
+	unsetEnvForTest(t, EnvKeyAppName, EnvKeyCurrentEnv, EnvKeyPort)
+
+	root := t.TempDir()
+	writeLayerFile(t, filepath.Join(root, "config/base.env"), "APP_NAME=patterns\nCURRENT_ENV=prod\n")
+	writeLayerFile(t, filepath.Join(root, "config/prod/app.env"), "PORT=443\n")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithFilePatterns("config/base.env", "config/%s/app.env"))
+	assert.NoError(t, err)
+	assert.Equal(t, "443", appEnv.Env().Value("PORT"))
+}
+
+func TestLoadAppEnv_Layers(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "env/secrets.env"), "LEVEL=secrets\nDB_PASSWORD=hunter2\n")
+
+	t.Run("later layers win", func(t *testing.T) {
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithLayers(
+			Layer{Name: "base", Path: DefaultFilePath},
+			Layer{Name: "secrets", Path: "env/secrets.env"},
+			Layer{Name: "env", Path: "env/%s.env"},
+		))
+		assert.NoError(t, err)
+		assert.Equal(t, "current", appEnv.Env().Value("LEVEL"))
+		assert.Equal(t, "hunter2", appEnv.Env().Value("DB_PASSWORD"))
+	})
+
+	t.Run("missing layers", func(t *testing.T) {
+		_, err := LoadAppEnv(WithBaseDir(root), WithLayers(
+			Layer{Name: "base", Path: DefaultFilePath},
+			Layer{Name: "optional", Path: "env/missing.env", Optional: true},
+			Layer{Name: "required", Path: "env/also-missing.env"},
+		))
+		assert.ErrorIs(t, err, ErrNoEnvFile)
+		assert.Contains(t, err.Error(), "layer required")
+		assert.NotContains(t, err.Error(), "layer optional")
+	})
+
+	t.Run("absolute paths ignore the base dir", func(t *testing.T) {
+		appEnv, err := LoadAppEnv(WithBaseDir(t.TempDir()), WithLayers(
+			Layer{Name: "base", Path: filepath.Join(root, DefaultFilePath)},
+		))
+		assert.NoError(t, err)
+		assert.Equal(t, "default", appEnv.Env().Value("LEVEL"))
+	})
+}
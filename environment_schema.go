@@ -0,0 +1,313 @@
+package siocore
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SourceSchema is the source of values filled in from an EnvSchema default.
+const SourceSchema = "schema"
+
+// EnvType is the type an EnvSchema expects a value to parse as.
+type EnvType string
+
+const (
+	EnvTypeString   EnvType = "string"
+	EnvTypeInt      EnvType = "int"
+	EnvTypeFloat    EnvType = "float"
+	EnvTypeBool     EnvType = "bool"
+	EnvTypeDuration EnvType = "duration"
+	EnvTypeURL      EnvType = "url"
+)
+
+var ErrEnvValueNotAllowed = errors.New("env value not allowed")
+
+// EnvVar declares a key of an EnvSchema.
+type EnvVar struct {
+	Key      string
+	Type     EnvType
+	Required bool
+	// Default is used when the key is missing or empty.
+	Default string
+	// Allowed lists the only values the key may have, if set.
+	Allowed []string
+	// Pattern must match the whole value, if set.
+	Pattern     *regexp.Regexp
+	Description string
+	// Secret masks the value in Describe and leaves it out of the sample env file.
+	Secret bool
+}
+
+// EnvSchema declares the keys an application reads, e.g.
+//
+//	var schema = siocore.EnvSchema{
+//		{Key: "PORT", Type: siocore.EnvTypeInt, Default: "8080", Description: "HTTP listen port"},
+//		{Key: "LOG_LEVEL", Allowed: []string{"debug", "info", "warn", "error"}, Default: "info"},
+//		{Key: "DB_PASSWORD", Required: true, Secret: true},
+//	}
+//
+// It is enforced by WithEnvSchema and renders the documentation of the keys with SampleEnv
+// and Markdown.
+type EnvSchema []EnvVar
+
+// WithEnvSchema fills in the defaults of schema and validates the loaded Env against it on
+// every load and reload. Every violation is an *EnvKeyError of the *LoadError.
+func WithEnvSchema(schema EnvSchema) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.schema = append(cfg.schema, schema...)
+	}
+}
+
+// ApplyDefaults sets the default of every key missing from env, returning the keys set.
+func (s EnvSchema) ApplyDefaults(env Env) []string {
+	var applied []string
+	for _, v := range s {
+		if _, ok := env.LookupValue(v.Key); ok || v.Default == "" {
+			continue
+		}
+
+		env[v.Key] = v.Default
+		applied = append(applied, v.Key)
+	}
+
+	return applied
+}
+
+// Validate checks env against the schema, returning every violation as an *EnvKeyError
+// joined by errors.Join, or nil. Missing keys are only violations if they are required.
+// The values of secret keys are left out of the errors.
+func (s EnvSchema) Validate(env Env) error {
+	return errors.Join(s.validate(env, true, nil)...)
+}
+
+// validate checks env against the schema, leaving missing required keys to the caller
+// unless checkRequired is set. The values of secret keys and keys in secrets, e.g.
+// decrypted ones, are left out of the errors.
+func (s EnvSchema) validate(env Env, checkRequired bool, secrets map[string]bool) []error {
+	var errs []error
+	for _, v := range s {
+		value, ok := env.LookupValue(v.Key)
+		if !ok {
+			if v.Required && checkRequired {
+				errs = append(errs, &EnvKeyError{Key: v.Key, Err: ErrEnvKeyNotFound})
+			}
+			continue
+		}
+
+		secret := v.Secret || secrets[v.Key]
+		if err := v.check(value, secret); err != nil {
+			keyErr := &EnvKeyError{Key: v.Key, Err: err}
+			if !secret {
+				keyErr.Value = value
+			}
+			errs = append(errs, keyErr)
+		}
+	}
+
+	return errs
+}
+
+// check validates value, leaving it out of the returned error if secret.
+func (v EnvVar) check(value string, secret bool) error {
+	var err error
+	switch v.Type {
+	case "", EnvTypeString:
+	case EnvTypeInt:
+		_, err = parseEnvInt(value)
+	case EnvTypeFloat:
+		_, err = parseEnvFloat(value)
+	case EnvTypeBool:
+		_, err = parseEnvBool(value)
+	case EnvTypeDuration:
+		_, err = parseEnvDuration(value)
+	case EnvTypeURL:
+		_, err = parseEnvURL(value)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedEnvType, v.Type)
+	}
+	if err != nil && secret {
+		// parse errors quote the value they failed on
+		return fmt.Errorf("%w: not a valid %s", ErrInvalidEnvValue, v.typ())
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(v.Allowed) > 0 && !slices.Contains(v.Allowed, value) {
+		return fmt.Errorf("%w: must be one of %s", ErrEnvValueNotAllowed, strings.Join(v.Allowed, ", "))
+	}
+
+	if v.Pattern != nil {
+		// anchored rather than comparing a match's bounds, as leftmost-first matching stops
+		// at the first alternative, e.g. a in a|ab, even when a later one matches everything
+		full := regexp.MustCompile(`^(?:` + v.Pattern.String() + `)$`)
+		if !full.MatchString(value) {
+			return fmt.Errorf("%w: must match %s", ErrInvalidEnvValue, v.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// keys returns every declared key.
+func (s EnvSchema) keys() []string {
+	keys := make([]string, 0, len(s))
+	for _, v := range s {
+		keys = append(keys, v.Key)
+	}
+
+	return keys
+}
+
+// requiredKeys returns the keys declared required.
+func (s EnvSchema) requiredKeys() []string {
+	var keys []string
+	for _, v := range s {
+		if v.Required {
+			keys = append(keys, v.Key)
+		}
+	}
+
+	return keys
+}
+
+// secretKeys returns the keys declared secret.
+func (s EnvSchema) secretKeys() []string {
+	var keys []string
+	for _, v := range s {
+		if v.Secret {
+			keys = append(keys, v.Key)
+		}
+	}
+
+	return keys
+}
+
+// SampleEnv renders the schema as a dotenv file documenting every key, with its default as
+// the value. Secrets are left empty. Defaults that need it are double quoted and escaped so
+// they load back unchanged.
+func (s EnvSchema) SampleEnv() string {
+	var sb strings.Builder
+	for i, v := range s {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+
+		if v.Description != "" {
+			writeDotenvComment(&sb, v.Description)
+		}
+		writeDotenvComment(&sb, v.summary())
+
+		value := v.Default
+		if v.Secret {
+			value = ""
+		}
+		sb.WriteString(v.Key + "=" + dotenvQuote(value) + "\n")
+	}
+
+	return sb.String()
+}
+
+// writeDotenvComment writes text as comment lines, each line behind its own #, so text
+// holding line breaks cannot add entries to the file.
+func writeDotenvComment(sb *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		sb.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+}
+
+// dotenvQuote double quotes value if it holds characters parseDotenv or interpolation would
+// not keep as written, escaping them.
+func dotenvQuote(value string) string {
+	if !strings.ContainsAny(value, " \t#\"'$\\\n\r") {
+		return value
+	}
+
+	return `"` + dotenvEscaper.Replace(value) + `"`
+}
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+
+// Markdown renders the schema as a markdown table with a row per key.
+func (s EnvSchema) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("| Key | Type | Required | Default | Allowed | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+
+	for _, v := range s {
+		allowed := make([]string, 0, len(v.Allowed))
+		for _, a := range v.Allowed {
+			allowed = append(allowed, markdownCode(a))
+		}
+		if v.Pattern != nil {
+			allowed = append(allowed, "matches "+markdownCode(v.Pattern.String()))
+		}
+
+		def := markdownCode(v.Default)
+		if v.Secret && v.Default != "" {
+			def = MaskedValue
+		}
+
+		cells := []string{
+			markdownCode(v.Key),
+			string(v.typ()),
+			yesNo(v.Required),
+			def,
+			strings.Join(allowed, ", "),
+			markdownEscape(v.Description),
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	return sb.String()
+}
+
+// summary describes the type and constraints of the key on one line.
+func (v EnvVar) summary() string {
+	parts := []string{string(v.typ())}
+	if v.Required {
+		parts = append(parts, "required")
+	}
+	if v.Secret {
+		parts = append(parts, "secret")
+	}
+	if len(v.Allowed) > 0 {
+		parts = append(parts, "one of: "+strings.Join(v.Allowed, "|"))
+	}
+	if v.Pattern != nil {
+		parts = append(parts, "matches: "+v.Pattern.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (v EnvVar) typ() EnvType {
+	if v.Type == "" {
+		return EnvTypeString
+	}
+
+	return v.Type
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + markdownEscape(s) + "`"
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -0,0 +1,196 @@
+package siocore
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = EnvSchema{
+	{Key: "PORT", Type: EnvTypeInt, Default: "8080", Description: "HTTP listen port"},
+	{Key: "LOG_LEVEL", Allowed: []string{"debug", "info"}, Default: "info"},
+	{Key: "REGION", Pattern: regexp.MustCompile(`[a-z]{2}-[a-z]+-\d`), Description: "cloud region | zone"},
+	{Key: "TIMEOUT", Type: EnvTypeDuration},
+	{Key: "DB_PASS", Required: true, Secret: true, Default: "changeme"},
+}
+
+func TestEnvSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      Env
+		wantErrs []error
+		wantKeys []string
+	}{
+		{
+			name: "valid",
+			env:  Env{"PORT": "80", "LOG_LEVEL": "debug", "REGION": "us-east-1", "TIMEOUT": "5s", "DB_PASS": "x"},
+		},
+		{
+			name:     "missing required",
+			env:      Env{"PORT": "80"},
+			wantErrs: []error{ErrEnvKeyNotFound},
+			wantKeys: []string{"DB_PASS"},
+		},
+		{
+			name:     "invalid values",
+			env:      Env{"PORT": "eighty", "LOG_LEVEL": "trace", "REGION": "us-east-1a", "TIMEOUT": "5", "DB_PASS": "x"},
+			wantErrs: []error{ErrInvalidEnvValue, ErrEnvValueNotAllowed, ErrInvalidEnvValue, ErrInvalidEnvValue},
+			wantKeys: []string{"PORT", "LOG_LEVEL", "REGION", "TIMEOUT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testSchema.Validate(tt.env)
+			if tt.wantErrs == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			errs := err.(interface{ Unwrap() []error }).Unwrap()
+			if assert.Len(t, errs, len(tt.wantErrs)) {
+				for i, want := range tt.wantErrs {
+					var keyErr *EnvKeyError
+					assert.True(t, errors.As(errs[i], &keyErr))
+					assert.Equal(t, tt.wantKeys[i], keyErr.Key)
+					assert.ErrorIs(t, errs[i], want)
+				}
+			}
+		})
+	}
+
+	assert.ErrorIs(t, EnvSchema{{Key: "A", Type: "uuid"}}.Validate(Env{"A": "x"}), ErrUnsupportedEnvType)
+
+	t.Run("pattern matches the whole value", func(t *testing.T) {
+		schema := EnvSchema{{Key: "A", Pattern: regexp.MustCompile(`a|ab`)}}
+
+		assert.NoError(t, schema.Validate(Env{"A": "ab"}))
+		assert.NoError(t, schema.Validate(Env{"A": "a"}))
+		assert.ErrorIs(t, schema.Validate(Env{"A": "abc"}), ErrInvalidEnvValue)
+		assert.ErrorIs(t, schema.Validate(Env{"A": "xab"}), ErrInvalidEnvValue)
+	})
+
+	t.Run("secret values are not in errors", func(t *testing.T) {
+		err := EnvSchema{{Key: "P", Type: EnvTypeInt, Secret: true}}.Validate(Env{"P": "hunter2"})
+		assert.ErrorIs(t, err, ErrInvalidEnvValue)
+		assert.NotContains(t, err.Error(), "hunter2")
+
+		errs := EnvSchema{{Key: "P", Type: EnvTypeInt}}.validate(Env{"P": "hunter2"}, false, map[string]bool{"P": true})
+		if assert.Len(t, errs, 1) {
+			assert.NotContains(t, errs[0].Error(), "hunter2")
+		}
+	})
+}
+
+func TestEnvSchema_ApplyDefaults(t *testing.T) {
+	env := Env{"PORT": "9090", "LOG_LEVEL": ""}
+
+	applied := testSchema.ApplyDefaults(env)
+	assert.Equal(t, []string{"LOG_LEVEL", "DB_PASS"}, applied)
+	assert.Equal(t, "9090", env["PORT"])
+	assert.Equal(t, "info", env["LOG_LEVEL"])
+	assert.NotContains(t, env, "REGION")
+}
+
+func TestEnvSchema_SampleEnv(t *testing.T) {
+	schema := EnvSchema{
+		{Key: "PORT", Type: EnvTypeInt, Default: "8080", Description: "HTTP listen port"},
+		{Key: "GREETING", Default: "hello world"},
+		{Key: "DB_PASS", Required: true, Secret: true, Default: "changeme"},
+	}
+
+	expected := `# HTTP listen port
+# int
+PORT=8080
+
+# string
+GREETING="hello world"
+
+# string, required, secret
+DB_PASS=
+`
+	assert.Equal(t, expected, schema.SampleEnv())
+
+	parsed, err := parseDotenv([]byte(testSchema.SampleEnv()))
+	assert.NoError(t, err, "the sample is a valid env file")
+	assert.Equal(t, "8080", parsed.values["PORT"])
+
+	t.Run("escaping", func(t *testing.T) {
+		schema := EnvSchema{
+			{Key: "MOTD", Default: "it's \"$HOME\" \\ ${X}\nline # 2", Description: "first\nLINE2=oops\r\nthird"},
+			{Key: "MODE", Allowed: []string{"a", "b\nINJECTED=1"}},
+		}
+
+		sample := schema.SampleEnv()
+		assert.Contains(t, sample, "# first\n# LINE2=oops\n# third\n")
+
+		file, err := parseDotenv([]byte(sample))
+		assert.NoError(t, err)
+		assert.Empty(t, interpolateEnv(file.values, nil, file.literals, true))
+		assert.Equal(t, Env{"MOTD": schema[0].Default, "MODE": ""}, file.values, "defaults load back unchanged")
+	})
+}
+
+func TestEnvSchema_Markdown(t *testing.T) {
+	expected := "| Key | Type | Required | Default | Allowed | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `PORT` | int | no | `8080` |  | HTTP listen port |\n" +
+		"| `LOG_LEVEL` | string | no | `info` | `debug`, `info` |  |\n" +
+		"| `REGION` | string | no |  | matches `[a-z]{2}-[a-z]+-\\d` | cloud region \\| zone |\n" +
+		"| `TIMEOUT` | duration | no |  |  |  |\n" +
+		"| `DB_PASS` | string | yes | ****** |  |  |\n"
+
+	assert.Equal(t, expected, testSchema.Markdown())
+}
+
+func TestLoadAppEnv_Schema(t *testing.T) {
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=s3cret\nLOG_LEVEL=debug\n")
+	unsetEnvForTest(t, "PORT", "LOG_LEVEL", "REGION", "TIMEOUT", "DB_PASS")
+	t.Setenv("TIMEOUT", "3s")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithEnvSchema(testSchema))
+	assert.NoError(t, err)
+
+	env := appEnv.Env()
+	assert.Equal(t, "8080", env.Value("PORT"), "set by the default layer")
+	assert.Equal(t, "debug", env.Value("LOG_LEVEL"))
+	assert.Equal(t, "3s", env.Value("TIMEOUT"), "schema keys are read from the process environment")
+	assert.Equal(t, SourceOS, appEnv.Source("TIMEOUT"))
+
+	for _, entry := range appEnv.Describe() {
+		if entry.Key == "DB_PASS" {
+			assert.True(t, entry.Secret)
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, DefaultFilePath), "APP_NAME=layers\nCURRENT_ENV=dev\n")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithEnvSchema(testSchema))
+		assert.NoError(t, err)
+		assert.Equal(t, "8080", appEnv.Env().Value("PORT"))
+		assert.Equal(t, SourceSchema, appEnv.Source("PORT"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LOG_LEVEL=trace\nDB_PASS=\n")
+
+		_, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithEnvSchema(testSchema), WithRequiredKeys("DB_PASS"))
+
+		var loadErr *LoadError
+		if assert.ErrorAs(t, err, &loadErr) {
+			assert.Len(t, loadErr.Errors, 1, "DB_PASS gets its default, LOG_LEVEL is not allowed")
+		}
+		assert.ErrorIs(t, err, ErrEnvValueNotAllowed)
+
+		_, err = LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithEnvSchema(EnvSchema{{Key: "API_URL", Required: true}}), WithRequiredKeys("API_URL"))
+		if assert.ErrorAs(t, err, &loadErr) {
+			assert.Len(t, loadErr.Errors, 1, "missing keys are reported once")
+		}
+		assert.ErrorIs(t, err, ErrEnvKeyNotFound)
+	})
+}
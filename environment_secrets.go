@@ -0,0 +1,296 @@
+package siocore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SecretSchemeSecret = "secret"
+	SecretSchemeFile   = "file"
+	SecretSchemeVault  = "vault"
+
+	// DefaultSecretTimeout bounds resolving the secrets of a load, see WithSecretTimeout.
+	DefaultSecretTimeout = 30 * time.Second
+	// DefaultSecretHTTPTimeout is the timeout of the default client of an HTTPSecretProvider.
+	DefaultSecretHTTPTimeout = 10 * time.Second
+)
+
+var (
+	ErrSecretNotFound   = errors.New("secret not found")
+	ErrSecretResolution = errors.New("unable to resolve secret")
+)
+
+// SecretProvider resolves secret references such as secret://name, file:///run/secrets/db
+// or vault://path#key into their values.
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// SecretProviderFunc adapts a function to a SecretProvider.
+type SecretProviderFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithSecretProvider resolves values whose URL scheme is scheme through provider once the
+// layers are merged and interpolated. Resolved keys are masked by Describe.
+func WithSecretProvider(scheme string, provider SecretProvider) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		if cfg.secretProviders == nil {
+			cfg.secretProviders = make(map[string]SecretProvider)
+		}
+		cfg.secretProviders[scheme] = provider
+	}
+}
+
+// WithSecretTimeout bounds how long a load or reload may spend resolving secrets, so an
+// unresponsive secret store fails the load instead of blocking it. Defaults to
+// DefaultSecretTimeout; zero or less disables the timeout.
+func WithSecretTimeout(timeout time.Duration) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.secretTimeout = timeout
+	}
+}
+
+// resolveSecrets replaces the values of env referring to a registered scheme with the
+// secret they refer to, returning the resolved keys and an *EnvKeyError for every failure.
+func resolveSecrets(ctx context.Context, env Env, providers map[string]SecretProvider) (map[string]bool, []error) {
+	resolved := make(map[string]bool)
+	var errs []error
+
+	for key, value := range env {
+		scheme, _, ok := strings.Cut(value, "://")
+		if !ok {
+			continue
+		}
+
+		provider, ok := providers[scheme]
+		if !ok {
+			continue
+		}
+
+		// the reference may hold credentials, so errors only show it redacted
+		ref, err := url.Parse(value)
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			errs = append(errs, &EnvKeyError{Key: key, Err: fmt.Errorf("%w: %w", ErrSecretResolution, err)})
+			continue
+		}
+
+		secret, err := provider.Resolve(ctx, ref)
+		if err != nil {
+			errs = append(errs, &EnvKeyError{Key: key, Value: ref.Redacted(), Err: fmt.Errorf("%w: %w", ErrSecretResolution, err)})
+			continue
+		}
+
+		env[key] = secret
+		resolved[key] = true
+	}
+
+	return resolved, errs
+}
+
+// FileSecretProvider resolves file:// references to the content of the file, with trailing
+// line breaks removed, e.g. file:///run/secrets/db_password for Docker and Kubernetes secrets.
+// Relative references such as file://secrets/db are resolved against Dir.
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p FileSecretProvider) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	path := filepath.FromSlash(ref.Host + ref.Path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Dir, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// HTTPSecretOption configures an HTTPSecretProvider.
+type HTTPSecretOption func(*HTTPSecretProvider)
+
+// WithSecretHTTPClient sets the http.Client used to fetch secrets. Defaults to a client with
+// a DefaultSecretHTTPTimeout timeout.
+func WithSecretHTTPClient(client *http.Client) HTTPSecretOption {
+	return func(p *HTTPSecretProvider) {
+		p.client = client
+	}
+}
+
+// WithSecretHeaders adds headers, e.g. X-Vault-Token, to every secret request.
+func WithSecretHeaders(headers map[string]string) HTTPSecretOption {
+	return func(p *HTTPSecretProvider) {
+		for key, value := range headers {
+			p.headers.Set(key, value)
+		}
+	}
+}
+
+// HTTPSecretProvider resolves references by fetching baseURL joined with the reference's
+// host and path, e.g. vault://secret/data/db#data.data.password fetches
+// <baseURL>/secret/data/db. The fragment is a dot separated path into the JSON response
+// selecting the secret; without a fragment the whole response body is the secret.
+type HTTPSecretProvider struct {
+	baseURL string
+	client  *http.Client
+	headers http.Header
+}
+
+// NewHTTPSecretProvider creates an HTTPSecretProvider fetching secrets from baseURL.
+func NewHTTPSecretProvider(baseURL string, opts ...HTTPSecretOption) *HTTPSecretProvider {
+	p := &HTTPSecretProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: DefaultSecretHTTPTimeout},
+		headers: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *HTTPSecretProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	endpoint := p.baseURL + "/" + strings.TrimLeft(ref.Host+ref.Path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	for key := range p.headers {
+		req.Header.Set(key, p.headers.Get(key))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, endpoint)
+	case res.StatusCode >= 300:
+		return "", fmt.Errorf("unexpected status %d from %s", res.StatusCode, endpoint)
+	}
+
+	if ref.Fragment == "" {
+		return strings.TrimRight(string(body), "\r\n"), nil
+	}
+
+	return jsonField(body, ref.Fragment)
+}
+
+// jsonField returns the value at the dot separated path in the JSON document body.
+func jsonField(body []byte, path string) (string, error) {
+	var current any
+	if err := json.Unmarshal(body, &current); err != nil {
+		return "", fmt.Errorf("decode secret response: %w", err)
+	}
+
+	for _, field := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+		}
+
+		if current, ok = object[field]; !ok {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value, nil
+	case nil:
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	default:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	}
+}
+
+// CachingSecretProvider caches the secrets resolved by another provider for a TTL, so
+// reloads do not fetch every secret again. Failures are not cached.
+type CachingSecretProvider struct {
+	provider SecretProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// NewCachingSecretProvider caches the secrets resolved by provider for ttl.
+func NewCachingSecretProvider(provider SecretProvider, ttl time.Duration) *CachingSecretProvider {
+	return &CachingSecretProvider{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]cachedSecret),
+	}
+}
+
+func (p *CachingSecretProvider) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	key := ref.String()
+
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	p.mu.Unlock()
+
+	if ok && p.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := p.provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.entries[key] = cachedSecret{value: value, expires: p.now().Add(p.ttl)}
+	p.mu.Unlock()
+
+	return value, nil
+}
+
+// Invalidate drops every cached secret.
+func (p *CachingSecretProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries = make(map[string]cachedSecret)
+}
@@ -0,0 +1,227 @@
+package siocore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secretServer stands in for a secret store, serving a Vault style KV document and a plain
+// text secret and counting the requests it receives.
+func secretServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/secret/data/db":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432}}}`))
+		case "/api-key":
+			_, _ = w.Write([]byte("abc123\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	assert.NoError(t, err)
+
+	return u
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeLayerFile(t, filepath.Join(dir, "db_password"), "s3cret\n")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{name: "absolute", ref: "file://" + filepath.ToSlash(filepath.Join(dir, "db_password")), want: "s3cret"},
+		{name: "relative to dir", ref: "file://db_password", want: "s3cret"},
+		{name: "missing", ref: "file://missing", wantErr: ErrSecretNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FileSecretProvider{Dir: dir}.Resolve(context.Background(), mustParseURL(t, tt.ref))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHTTPSecretProvider(t *testing.T) {
+	srv, _ := secretServer(t)
+	provider := NewHTTPSecretProvider(srv.URL+"/", WithSecretHeaders(map[string]string{"X-Vault-Token": "root"}))
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{name: "json field", ref: "vault://secret/data/db#data.data.password", want: "hunter2"},
+		{name: "non string field", ref: "vault://secret/data/db#data.data.port", want: "5432"},
+		{name: "plain body", ref: "secret://api-key", want: "abc123"},
+		{name: "missing field", ref: "vault://secret/data/db#data.data.user", wantErr: ErrSecretNotFound},
+		{name: "missing secret", ref: "vault://secret/data/nope#password", wantErr: ErrSecretNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.Resolve(context.Background(), mustParseURL(t, tt.ref))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("default client timeout", func(t *testing.T) {
+		assert.Equal(t, DefaultSecretHTTPTimeout, provider.client.Timeout)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		_, err := NewHTTPSecretProvider(srv.URL).Resolve(context.Background(), mustParseURL(t, "secret://api-key"))
+		assert.ErrorContains(t, err, "unexpected status 403")
+	})
+}
+
+func TestCachingSecretProvider(t *testing.T) {
+	var calls int
+	fail := false
+	provider := NewCachingSecretProvider(SecretProviderFunc(func(context.Context, *url.URL) (string, error) {
+		calls++
+		if fail {
+			return "", errors.New("unavailable")
+		}
+		return "value", nil
+	}), time.Minute)
+
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+	ref := mustParseURL(t, "secret://name")
+
+	for i := 0; i < 3; i++ {
+		got, err := provider.Resolve(context.Background(), ref)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", got)
+	}
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	fail = true
+	_, err := provider.Resolve(context.Background(), ref)
+	assert.Error(t, err, "expired entries are resolved again")
+
+	fail = false
+	_, err = provider.Resolve(context.Background(), ref)
+	assert.NoError(t, err, "failures are not cached")
+	assert.Equal(t, 3, calls)
+
+	provider.Invalidate()
+	_, err = provider.Resolve(context.Background(), ref)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestLoadAppEnv_Secrets(t *testing.T) {
+	srv, requests := secretServer(t)
+	root := layeredProject(t)
+	writeLayerFile(t, filepath.Join(root, "secrets/db_password"), "s3cret\n")
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=file://secrets/db_password\nVAULT_DB=vault://secret/data/db#data.data.password\nPLAIN=https://example.com\n")
+
+	vault := NewCachingSecretProvider(NewHTTPSecretProvider(srv.URL, WithSecretHeaders(map[string]string{"X-Vault-Token": "root"})), time.Hour)
+
+	appEnv, err := LoadAppEnv(
+		WithBaseDir(root),
+		WithoutSystemMutation(),
+		WithSecretProvider(SecretSchemeFile, FileSecretProvider{Dir: root}),
+		WithSecretProvider(SecretSchemeVault, vault),
+	)
+	assert.NoError(t, err)
+
+	env := appEnv.Env()
+	assert.Equal(t, "s3cret", env.Value("DB_PASS"))
+	assert.Equal(t, "hunter2", env.Value("VAULT_DB"))
+	assert.Equal(t, "https://example.com", env.Value("PLAIN"), "schemes without a provider are left alone")
+
+	for _, entry := range appEnv.Describe() {
+		if entry.Key == "VAULT_DB" {
+			assert.True(t, entry.Secret)
+			assert.Equal(t, MaskedValue, entry.Value)
+		}
+	}
+
+	assert.NoError(t, appEnv.Reload())
+	assert.Equal(t, int32(1), requests.Load(), "reloads use the cached secret")
+
+	t.Run("errors", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, "env/dev.env"), "DB_PASS=file://secrets/missing\nVAULT_DB=vault://secret/data/nope#password\n")
+
+		_, err := LoadAppEnv(
+			WithBaseDir(root),
+			WithoutSystemMutation(),
+			WithSecretProvider(SecretSchemeFile, FileSecretProvider{Dir: root}),
+			WithSecretProvider(SecretSchemeVault, vault),
+		)
+
+		var loadErr *LoadError
+		if assert.ErrorAs(t, err, &loadErr) {
+			assert.Len(t, loadErr.Errors, 2)
+		}
+		assert.ErrorIs(t, err, ErrSecretResolution)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.ErrorContains(t, err, "DB_PASS")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		writeLayerFile(t, filepath.Join(root, "env/dev.env"), "VAULT_DB=vault://secret/data/db#data.data.password\n")
+		hanging := SecretProviderFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+
+		_, err := LoadAppEnv(
+			WithBaseDir(root),
+			WithoutSystemMutation(),
+			WithSecretProvider(SecretSchemeVault, hanging),
+			WithSecretTimeout(10*time.Millisecond),
+		)
+		assert.ErrorIs(t, err, ErrSecretResolution)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
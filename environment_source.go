@@ -0,0 +1,78 @@
+package siocore
+
+import (
+	"os"
+	"strings"
+)
+
+// SourceOS is the source of values taken from the process environment.
+const SourceOS = "os"
+
+// Precedence decides whether process environment variables or env file values win when
+// both define a key.
+type Precedence int
+
+const (
+	// OSFirst keeps variables already set in the process environment, e.g. injected by
+	// Kubernetes, over values from env files. This is the default.
+	OSFirst Precedence = iota
+	// FilesFirst lets env file values override the process environment.
+	FilesFirst
+)
+
+// WithPrecedence sets whether the process environment or env files win, defaults to OSFirst.
+// With OSFirst a CURRENT_ENV set in the process environment also selects the current layer.
+func WithPrecedence(p Precedence) AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.precedence = p
+	}
+}
+
+// WithoutSystemMutation leaves the process environment untouched instead of setting the
+// loaded values with os.Setenv. Secrets, i.e. decrypted, resolved and schema secret values,
+// are never set.
+func WithoutSystemMutation() AppEnvOption {
+	return func(cfg *appEnvConfig) {
+		cfg.noSystemMutation = true
+	}
+}
+
+// Source returns where the effective value of key came from: the name of the layer that
+// last set it, SourceOS, or "" if key was not loaded.
+func (ae *AppEnv) Source(key string) string {
+	return ae.snapshot.Load().sources[key]
+}
+
+// Sources returns the source of every loaded key, see Source.
+func (ae *AppEnv) Sources() map[string]string {
+	return MergeMaps(ae.snapshot.Load().sources)
+}
+
+// systemEnv returns the process environment as an Env.
+func systemEnv() Env {
+	env := make(Env)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return env
+}
+
+// applySystemEnv overrides env with the values osEnv holds for its keys and for extraKeys,
+// recording SourceOS for each.
+func applySystemEnv(env Env, sources map[string]string, osEnv Env, extraKeys []string) {
+	keys := make([]string, 0, len(env)+len(extraKeys))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	keys = append(keys, extraKeys...)
+
+	for _, key := range keys {
+		if value, ok := osEnv.LookupValue(key); ok {
+			env[key] = value
+			sources[key] = SourceOS
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package siocore
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAppEnv_Precedence(t *testing.T) {
+	root := layeredProject(t)
+
+	t.Run("os wins by default", func(t *testing.T) {
+		t.Setenv("LEVEL", "injected")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root))
+		assert.NoError(t, err)
+		assert.Equal(t, "injected", appEnv.Env().Value("LEVEL"))
+		assert.Equal(t, SourceOS, appEnv.Source("LEVEL"))
+		assert.Equal(t, "injected", os.Getenv("LEVEL"))
+	})
+
+	t.Run("files first", func(t *testing.T) {
+		t.Setenv("LEVEL", "injected")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithPrecedence(FilesFirst))
+		assert.NoError(t, err)
+		assert.Equal(t, "current", appEnv.Env().Value("LEVEL"))
+		assert.Equal(t, LayerCurrentEnv, appEnv.Source("LEVEL"))
+		assert.Equal(t, "current", os.Getenv("LEVEL"))
+	})
+
+	t.Run("os selects the current layer", func(t *testing.T) {
+		writeLayerFile(t, root+"/env/prod.env", "LEVEL=prod\n")
+		t.Setenv(EnvKeyCurrentEnv, "prod")
+		unsetEnvForTest(t, "LEVEL")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", appEnv.Env().Value("LEVEL"))
+		assert.Equal(t, SourceOS, appEnv.Source(EnvKeyCurrentEnv))
+	})
+
+	t.Run("required keys may come from os only", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "from-secret")
+
+		appEnv, err := LoadAppEnv(WithBaseDir(root), WithRequiredKeys("DB_PASSWORD"), WithoutSystemMutation())
+		assert.NoError(t, err)
+		assert.Equal(t, "from-secret", appEnv.Env().Value("DB_PASSWORD"))
+	})
+}
+
+func TestLoadAppEnv_WithoutSystemMutation(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+	assert.NoError(t, err)
+	assert.Equal(t, "layers", appEnv.Env().Value(EnvKeyAppName))
+
+	_, set := os.LookupEnv(EnvKeyAppName)
+	assert.False(t, set)
+	_, set = os.LookupEnv("DB_HOST")
+	assert.False(t, set)
+}
+
+func TestAppEnv_Sources(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithLocalOverride(), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		EnvKeyAppName:    LayerDefault,
+		EnvKeyCurrentEnv: LayerDefault,
+		EnvKeyPort:       LayerDefault,
+		"DB_HOST":        LayerCurrentEnv,
+		"LEVEL":          LayerLocal,
+	}, appEnv.Sources())
+	assert.Empty(t, appEnv.Source("MISSING"))
+}
@@ -28,6 +28,16 @@ func EnvSetup(t *testing.T) {
 
 }
 
+// unsetEnvForTest unsets keys in the process environment for the duration of the test.
+func unsetEnvForTest(t *testing.T, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		t.Setenv(key, "")
+		assert.NoError(t, os.Unsetenv(key))
+	}
+}
+
 func EnvCleanup(t *testing.T) {
 	t.Helper()
 
@@ -43,6 +53,7 @@ func TestAppNewEnv(t *testing.T) {
 			assert.Equalf(t, os.Getenv(key), value, "expected %v, got %v", os.Getenv(key), value)
 		}
 	}
+	unsetEnvForTest(t, EnvKeyAppName, EnvKeyCurrentEnv, EnvKeyPort)
 	EnvSetup(t)
 	EnvCleanup(t)
 
@@ -211,3 +222,78 @@ func TestAppEnv_Update(t *testing.T) {
 	})
 
 }
+
+func TestLoadAppEnv(t *testing.T) {
+	unsetEnvForTest(t, EnvKeyAppName, EnvKeyCurrentEnv, EnvKeyPort, "test1", "test2")
+	EnvSetup(t)
+	EnvCleanup(t)
+
+	appEnv, err := LoadAppEnv(WithRequiredKeys("test1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "go-webserver", appEnv.Env().Value(EnvKeyAppName))
+	assert.Equal(t, "test", appEnv.Env().Value("test1"))
+}
+
+func TestLoadAppEnv_Errors(t *testing.T) {
+	unsetEnvForTest(t, EnvKeyAppName, EnvKeyCurrentEnv, EnvKeyPort, "test1", "test2")
+
+	t.Run("missing default file", func(t *testing.T) {
+		_, err := LoadAppEnv()
+
+		var loadErr *LoadError
+		assert.ErrorAs(t, err, &loadErr)
+		assert.ErrorIs(t, err, ErrNoEnvFile)
+		assert.ErrorIs(t, err, ErrEnvKeyNotFound, "missing keys are reported alongside the missing file")
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		siotest.CreateFile(t, DefaultFilePath)
+		siotest.WriteEnvToFile(t, DefaultFilePath, Env{EnvKeyCurrentEnv: "test"})
+		EnvCleanup(t)
+
+		_, err := LoadAppEnv(WithRequiredKeys("DB_HOST"))
+
+		var loadErr *LoadError
+		if assert.ErrorAs(t, err, &loadErr) {
+			assert.Len(t, loadErr.Errors, 2)
+		}
+		assert.Contains(t, err.Error(), "APP_NAME: env key not found")
+		assert.Contains(t, err.Error(), "DB_HOST: env key not found")
+	})
+
+	t.Run("malformed environment specific file", func(t *testing.T) {
+		EnvSetup(t)
+		EnvCleanup(t)
+		assert.NoError(t, os.WriteFile(fmt.Sprintf(CurrentEnvFilePath, "test"), []byte("this is bad\n"), 0o600))
+
+		_, err := LoadAppEnv()
+		assert.ErrorIs(t, err, ErrInvalidEnvFile)
+	})
+
+	t.Run("NewAppEnv panics", func(t *testing.T) {
+		assert.Panics(t, func() { NewAppEnv() })
+	})
+}
+
+func TestEnv_RequireValues(t *testing.T) {
+	env := Env{"present": "value", "empty": ""}
+
+	assert.NoError(t, env.RequireValues("present"))
+
+	err := env.RequireValues("present", "empty", "missing")
+	assert.ErrorIs(t, err, ErrEnvKeyNotFound)
+	assert.Contains(t, err.Error(), "empty: env key not found")
+	assert.Contains(t, err.Error(), "missing: env key not found")
+}
+
+func TestLookupAppName(t *testing.T) {
+	siotest.SetEnvVarForTest(t, EnvKeyAppName, "orders")
+
+	appName, err := LookupAppName()
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", appName)
+
+	assert.NoError(t, os.Unsetenv(EnvKeyAppName))
+	_, err = LookupAppName()
+	assert.ErrorIs(t, err, ErrNoAppName)
+}
@@ -0,0 +1,122 @@
+package siocore
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Int returns the value of key parsed as an int. Missing keys return an *EnvKeyError wrapping
+// ErrEnvKeyNotFound and malformed values one wrapping ErrInvalidEnvValue.
+func (e Env) Int(key string) (int, error) {
+	return envValue(e, key, parseEnvInt)
+}
+
+// IntOr is like Int but returns def when key is missing.
+func (e Env) IntOr(key string, def int) (int, error) {
+	return envValueOr(e, key, def, parseEnvInt)
+}
+
+// Bool returns the value of key parsed by strconv.ParseBool.
+func (e Env) Bool(key string) (bool, error) {
+	return envValue(e, key, parseEnvBool)
+}
+
+// BoolOr is like Bool but returns def when key is missing.
+func (e Env) BoolOr(key string, def bool) (bool, error) {
+	return envValueOr(e, key, def, parseEnvBool)
+}
+
+// Duration returns the value of key parsed by time.ParseDuration, e.g. "1m30s".
+func (e Env) Duration(key string) (time.Duration, error) {
+	return envValue(e, key, parseEnvDuration)
+}
+
+// DurationOr is like Duration but returns def when key is missing.
+func (e Env) DurationOr(key string, def time.Duration) (time.Duration, error) {
+	return envValueOr(e, key, def, parseEnvDuration)
+}
+
+// Float returns the value of key parsed as a float64.
+func (e Env) Float(key string) (float64, error) {
+	return envValue(e, key, parseEnvFloat)
+}
+
+// FloatOr is like Float but returns def when key is missing.
+func (e Env) FloatOr(key string, def float64) (float64, error) {
+	return envValueOr(e, key, def, parseEnvFloat)
+}
+
+// StringSlice returns the value of key split on sep, with spaces trimmed and empty elements dropped.
+func (e Env) StringSlice(key, sep string) ([]string, error) {
+	return envValue(e, key, stringSliceParser(sep))
+}
+
+// StringSliceOr is like StringSlice but returns def when key is missing.
+func (e Env) StringSliceOr(key, sep string, def []string) ([]string, error) {
+	return envValueOr(e, key, def, stringSliceParser(sep))
+}
+
+// URL returns the value of key parsed as an absolute URL.
+func (e Env) URL(key string) (*url.URL, error) {
+	return envValue(e, key, parseEnvURL)
+}
+
+// URLOr is like URL but returns def when key is missing.
+func (e Env) URLOr(key string, def *url.URL) (*url.URL, error) {
+	return envValueOr(e, key, def, parseEnvURL)
+}
+
+func envValue[T any](e Env, key string, parse func(string) (T, error)) (T, error) {
+	raw, ok := e.LookupValue(key)
+	if !ok {
+		var zero T
+		return zero, &EnvKeyError{Key: key, Err: ErrEnvKeyNotFound}
+	}
+
+	return parseEnvValue(key, raw, parse)
+}
+
+func envValueOr[T any](e Env, key string, def T, parse func(string) (T, error)) (T, error) {
+	raw, ok := e.LookupValue(key)
+	if !ok {
+		return def, nil
+	}
+
+	return parseEnvValue(key, raw, parse)
+}
+
+func parseEnvValue[T any](key, raw string, parse func(string) (T, error)) (T, error) {
+	value, err := parse(raw)
+	if err != nil {
+		var zero T
+		return zero, &EnvKeyError{Key: key, Value: raw, Err: err}
+	}
+
+	return value, nil
+}
+
+func parseEnvInt(raw string) (int, error) {
+	i, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+	}
+
+	return i, nil
+}
+
+func parseEnvFloat(raw string) (float64, error) {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidEnvValue, err)
+	}
+
+	return f, nil
+}
+
+func stringSliceParser(sep string) func(string) ([]string, error) {
+	return func(raw string) ([]string, error) {
+		return splitEnvList(raw, sep), nil
+	}
+}
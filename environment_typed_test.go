@@ -0,0 +1,106 @@
+package siocore
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var typedEnv = Env{
+	"PORT":     "8080",
+	"DEBUG":    "true",
+	"TIMEOUT":  "1m30s",
+	"RATIO":    "0.5",
+	"HOSTS":    "a, b,,c",
+	"UPSTREAM": "https://api.example.com",
+	"BAD":      "not-a-value",
+	"RELATIVE": "/just/a/path",
+}
+
+func TestEnv_TypedGetters(t *testing.T) {
+	port, err := typedEnv.Int("PORT")
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	debug, err := typedEnv.Bool("DEBUG")
+	assert.NoError(t, err)
+	assert.True(t, debug)
+
+	timeout, err := typedEnv.Duration("TIMEOUT")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	ratio, err := typedEnv.Float("RATIO")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, ratio)
+
+	hosts, err := typedEnv.StringSlice("HOSTS", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, hosts)
+
+	upstream, err := typedEnv.URL("UPSTREAM")
+	assert.NoError(t, err)
+	assert.Equal(t, "api.example.com", upstream.Host)
+}
+
+func TestEnv_TypedGetters_Errors(t *testing.T) {
+	tt := []struct {
+		name     string
+		get      func() error
+		expected error
+	}{
+		{name: "int missing", get: func() error { _, err := typedEnv.Int("MISSING"); return err }, expected: ErrEnvKeyNotFound},
+		{name: "int malformed", get: func() error { _, err := typedEnv.Int("BAD"); return err }, expected: ErrInvalidEnvValue},
+		{name: "bool malformed", get: func() error { _, err := typedEnv.Bool("BAD"); return err }, expected: ErrInvalidEnvValue},
+		{name: "duration malformed", get: func() error { _, err := typedEnv.Duration("PORT"); return err }, expected: ErrInvalidEnvValue},
+		{name: "float malformed", get: func() error { _, err := typedEnv.Float("BAD"); return err }, expected: ErrInvalidEnvValue},
+		{name: "slice missing", get: func() error { _, err := typedEnv.StringSlice("MISSING", ","); return err }, expected: ErrEnvKeyNotFound},
+		{name: "url not absolute", get: func() error { _, err := typedEnv.URL("RELATIVE"); return err }, expected: ErrInvalidEnvValue},
+		{name: "or variant malformed", get: func() error { _, err := typedEnv.IntOr("BAD", 1); return err }, expected: ErrInvalidEnvValue},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.get()
+
+			assert.ErrorIs(t, err, tc.expected)
+
+			var keyErr *EnvKeyError
+			assert.ErrorAs(t, err, &keyErr)
+		})
+	}
+}
+
+func TestEnv_TypedGetters_Or(t *testing.T) {
+	fallback, _ := url.Parse("http://localhost")
+
+	port, err := typedEnv.IntOr("MISSING", 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, port)
+
+	port, err = typedEnv.IntOr("PORT", 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	debug, err := typedEnv.BoolOr("MISSING", true)
+	assert.NoError(t, err)
+	assert.True(t, debug)
+
+	timeout, err := typedEnv.DurationOr("MISSING", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+
+	ratio, err := typedEnv.FloatOr("MISSING", 1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, ratio)
+
+	hosts, err := typedEnv.StringSliceOr("MISSING", ",", []string{"x"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, hosts)
+
+	upstream, err := typedEnv.URLOr("MISSING", fallback)
+	assert.NoError(t, err)
+	assert.Same(t, fallback, upstream)
+}
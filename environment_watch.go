@@ -0,0 +1,220 @@
+package siocore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"maps"
+	"os"
+	"sort"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval Watch uses for intervals of zero or less.
+const DefaultWatchInterval = 5 * time.Second
+
+// EnvDiff describes the keys changed by a reload of an AppEnv.
+type EnvDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+	// Old and New are the environments before and after the reload.
+	Old Env
+	New Env
+}
+
+// IsEmpty reports whether the reload changed nothing.
+func (d EnvDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Has reports whether key was added, removed or changed.
+func (d EnvDiff) Has(key string) bool {
+	return d.Old[key] != d.New[key] || hasKey(d.Old, key) != hasKey(d.New, key)
+}
+
+// DiffEnvs returns the keys added, removed and changed going from old to updated, each sorted.
+func DiffEnvs(old, updated Env) EnvDiff {
+	diff := EnvDiff{Old: old, New: updated}
+
+	for key, value := range updated {
+		oldValue, ok := old[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key)
+		case oldValue != value:
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range old {
+		if !hasKey(updated, key) {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+
+	return diff
+}
+
+// Subscribe registers fn to be called with the diff of every reload that changes the
+// environment. Subscribers are called after the reload completed, so they may call Reload,
+// Subscribe or unsubscribe themselves. The returned function removes fn again.
+func (ae *AppEnv) Subscribe(fn func(EnvDiff)) (unsubscribe func()) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	id := ae.nextSubID
+	ae.nextSubID++
+	ae.subscribers[id] = fn
+
+	return func() {
+		ae.mu.Lock()
+		defer ae.mu.Unlock()
+
+		delete(ae.subscribers, id)
+	}
+}
+
+// Reload reads the layers again. If they load and validate, the new environment is
+// swapped in, applied to the process environment unless WithoutSystemMutation is set and
+// subscribers are notified of the changes. Otherwise the current environment is kept and
+// the *LoadError is returned.
+func (ae *AppEnv) Reload() error {
+	diff, subscribers, err := ae.reload()
+	if err != nil || diff.IsEmpty() {
+		return err
+	}
+
+	for _, fn := range subscribers {
+		fn(diff)
+	}
+
+	return nil
+}
+
+// reload swaps in the new environment under ae.mu, returning the diff and the subscribers
+// to notify once the lock is released.
+func (ae *AppEnv) reload() (EnvDiff, []func(EnvDiff), error) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	snapshot, err := ae.load()
+	if err != nil {
+		return EnvDiff{}, nil, err
+	}
+
+	old := ae.snapshot.Load()
+	// subscribers get copies so they cannot modify the shared snapshots
+	diff := DiffEnvs(maps.Clone(old.env), maps.Clone(snapshot.env))
+
+	if !ae.cfg.noSystemMutation {
+		if err := applyDiffToSystem(diff, ae.systemEnv, snapshot.secrets); err != nil {
+			return EnvDiff{}, nil, &LoadError{Errors: []error{err}}
+		}
+	}
+
+	ae.snapshot.Store(snapshot)
+
+	subscribers := make([]func(EnvDiff), 0, len(ae.subscribers))
+	for _, fn := range ae.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+
+	return diff, subscribers, nil
+}
+
+// Watch polls the layer files every interval and reloads the AppEnv when any of them was
+// created, modified or removed since it was last loaded. Failed reloads are logged and the
+// current environment is kept until the files change again. Watch blocks until ctx is done.
+// Intervals of zero or less use DefaultWatchInterval.
+func (ae *AppEnv) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var failed map[string]string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := currentStamps(ae.snapshot.Load().stamps)
+		if maps.Equal(current, ae.snapshot.Load().stamps) || maps.Equal(current, failed) {
+			continue
+		}
+
+		if err := ae.Reload(); err != nil {
+			slog.Error("unable to reload env", "error", err)
+			failed = current
+		}
+	}
+}
+
+// currentStamps hashes the files of stamps as they are now, "" for missing ones.
+func currentStamps(stamps map[string]string) map[string]string {
+	current := make(map[string]string, len(stamps))
+	for path := range stamps {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			current[path] = ""
+			continue
+		}
+
+		current[path] = contentStamp(content)
+	}
+
+	return current
+}
+
+func contentStamp(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// applyDiffToSystem sets added and changed keys in the process environment. Removed keys
+// and secrets, whose plaintext is never set, are restored to their value in systemEnv, the
+// environment from before loading, or unset.
+func applyDiffToSystem(diff EnvDiff, systemEnv Env, secrets map[string]bool) error {
+	restore := diff.Removed
+	for _, keys := range [][]string{diff.Added, diff.Changed} {
+		for _, key := range keys {
+			if secrets[key] {
+				restore = append(restore[:len(restore):len(restore)], key)
+				continue
+			}
+			if err := os.Setenv(key, diff.New[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range restore {
+		if value, ok := systemEnv[key]; ok {
+			if err := os.Setenv(key, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.Unsetenv(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasKey(env Env, key string) bool {
+	_, ok := env[key]
+	return ok
+}
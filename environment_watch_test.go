@@ -0,0 +1,190 @@
+package siocore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffEnvs(t *testing.T) {
+	diff := DiffEnvs(
+		Env{"KEEP": "1", "CHANGE": "old", "REMOVE": "x"},
+		Env{"KEEP": "1", "CHANGE": "new", "ADD": "y"},
+	)
+
+	assert.Equal(t, []string{"ADD"}, diff.Added)
+	assert.Equal(t, []string{"REMOVE"}, diff.Removed)
+	assert.Equal(t, []string{"CHANGE"}, diff.Changed)
+	assert.True(t, diff.Has("CHANGE"))
+	assert.True(t, diff.Has("REMOVE"))
+	assert.False(t, diff.Has("KEEP"))
+	assert.False(t, diff.IsEmpty())
+	assert.True(t, DiffEnvs(Env{"A": "1"}, Env{"A": "1"}).IsEmpty())
+}
+
+func TestAppEnv_Reload(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root))
+	assert.NoError(t, err)
+	before := appEnv.Env()
+
+	var diffs []EnvDiff
+	unsubscribe := appEnv.Subscribe(func(diff EnvDiff) {
+		diffs = append(diffs, diff)
+	})
+
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LEVEL=reloaded\nFEATURE_X=true\n")
+	assert.NoError(t, appEnv.Reload())
+
+	assert.Equal(t, "reloaded", appEnv.Env().Value("LEVEL"))
+	assert.Equal(t, "current", before.Value("LEVEL"), "the previous Env is not modified")
+	assert.Equal(t, "reloaded", os.Getenv("LEVEL"))
+	_, set := os.LookupEnv("DB_HOST")
+	assert.False(t, set, "removed keys are unset")
+
+	if assert.Len(t, diffs, 1) {
+		assert.Equal(t, []string{"FEATURE_X"}, diffs[0].Added)
+		assert.Equal(t, []string{"DB_HOST"}, diffs[0].Removed)
+		assert.Equal(t, []string{"LEVEL"}, diffs[0].Changed)
+	}
+
+	assert.NoError(t, appEnv.Reload())
+	assert.Len(t, diffs, 1, "reloads without changes do not notify")
+
+	unsubscribe()
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LEVEL=again\n")
+	assert.NoError(t, appEnv.Reload())
+	assert.Len(t, diffs, 1)
+}
+
+func TestAppEnv_Reload_SubscriberReentry(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	calls := 0
+	var unsubscribe func()
+	unsubscribe = appEnv.Subscribe(func(diff EnvDiff) {
+		calls++
+		diff.New["LEVEL"] = "tampered"
+		appEnv.Subscribe(func(EnvDiff) {})()
+		assert.NoError(t, appEnv.Reload())
+		unsubscribe()
+	})
+
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LEVEL=reloaded\n")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, appEnv.Reload())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a subscriber calling back into the AppEnv deadlocked")
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "reloaded", appEnv.Env().Value("LEVEL"), "subscribers cannot modify the environment")
+}
+
+func TestAppEnv_Env_Copy(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	appEnv.Env().Update("LEVEL", "changed")
+	assert.Equal(t, "current", appEnv.Env().Value("LEVEL"))
+	assert.Equal(t, "current", appEnv.Value("LEVEL"))
+
+	value, ok := appEnv.LookupValue("LEVEL")
+	assert.True(t, ok)
+	assert.Equal(t, "current", value)
+
+	_, ok = appEnv.LookupValue("MISSING")
+	assert.False(t, ok)
+	assert.Empty(t, appEnv.Value("MISSING"))
+}
+
+func TestAppEnv_Reload_Invalid(t *testing.T) {
+	root := layeredProject(t)
+	errInvalidPort := errors.New("invalid port")
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation(), WithEnvValidator(func(env Env) error {
+		if _, err := env.Int(EnvKeyPort); err != nil {
+			return errInvalidPort
+		}
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	writeLayerFile(t, filepath.Join(root, DefaultFilePath), "APP_NAME=layers\nCURRENT_ENV=dev\nPORT=http\n")
+	assert.ErrorIs(t, appEnv.Reload(), errInvalidPort)
+	assert.Equal(t, "8080", appEnv.Env().Value(EnvKeyPort), "the current environment is kept")
+
+	writeLayerFile(t, filepath.Join(root, DefaultFilePath), "CURRENT_ENV=dev\nPORT=9090\n")
+	assert.ErrorIs(t, appEnv.Reload(), ErrEnvKeyNotFound)
+	assert.Equal(t, "8080", appEnv.Env().Value(EnvKeyPort))
+}
+
+func TestAppEnv_Watch(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	changed := make(chan EnvDiff, 1)
+	appEnv.Subscribe(func(diff EnvDiff) {
+		mu.Lock()
+		defer mu.Unlock()
+		changed <- diff
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		appEnv.Watch(ctx, 10*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	writeLayerFile(t, filepath.Join(root, LocalFilePath), "LOG_LEVEL=debug\n")
+	writeLayerFile(t, filepath.Join(root, "env/dev.env"), "LOG_LEVEL=info\nLEVEL=watched\n")
+
+	select {
+	case diff := <-changed:
+		assert.True(t, diff.Has("LEVEL"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not reload the changed file")
+	}
+	assert.Equal(t, "watched", appEnv.Env().Value("LEVEL"))
+}
+
+func TestAppEnv_Watch_NonPositiveInterval(t *testing.T) {
+	root := layeredProject(t)
+
+	appEnv, err := LoadAppEnv(WithBaseDir(root), WithoutSystemMutation())
+	assert.NoError(t, err)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.NotPanics(t, func() { appEnv.Watch(ctx, interval) })
+	}
+}
@@ -1,13 +1,17 @@
 package siocore
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
+	"time"
 )
 
 var (
 	DecryptionFailed      = "Decryption failed - unable to proceed error: %v"
+	DecryptionFailedMsg   = "Decryption failed - unable to proceed"
 	InvalidEmailErr       = "invalid email"
 	InvalidPasswordErr    = "invalid password: Requirements are 8 char min, 1 upper, 1 special, and 1 numerical"
 	InvalidNameErr        = "invalid name"
@@ -18,54 +22,304 @@ var (
 	InvalidEndDateErr     = "end date must be after start date"
 	TokenInvalid          = "unauthorized"
 	INVALID_ID            = "id must be numerical"
+	RequiredErr           = "field is required"
 )
 
+const maxStackDepth = 32
+
+// AppError is an error carrying the HTTP status code to respond with. It records the
+// call site stack when created and may wrap an underlying cause, which is exposed
+// through Unwrap so errors.Is and errors.As traverse the whole chain.
 type AppError struct {
-	error
-	Code int
+	Message string
+	Code    int
+	// Type, Instance and Details map to the type, instance and extension members of an
+	// RFC 7807 problem details response.
+	Type     string
+	Instance string
+	Details  map[string]any
+	// ErrorCode is the stable, machine-readable code of the error, see ErrorCatalog.
+	ErrorCode ErrorCode
+	// RetryAfter is how long the client should wait before retrying, sent as the Retry-After header.
+	RetryAfter time.Duration
+	cause      error
+	stack      []uintptr
+	// args are the arguments the catalog message template was rendered with.
+	args []any
 }
 
 func NewAppError(message string, code int) *AppError {
+	return newAppError(message, code, nil)
+}
+
+// Wrap creates an AppError with the given code and message that wraps err, preserving its chain.
+// Wrap returns nil if err is nil.
+func Wrap(err error, code int, message string) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	return newAppError(message, code, err)
+}
+
+// newAppError must be called directly by the exported constructors so the recorded stack
+// starts at their caller.
+func newAppError(message string, code int, cause error) *AppError {
+	return newAppErrorAt(2, message, code, cause)
+}
+
+// newAppErrorAt records the stack starting depth frames above its caller.
+func newAppErrorAt(depth int, message string, code int, cause error) *AppError {
 	return &AppError{
-		error: fmt.Errorf(message),
-		Code:  code,
+		Message: message,
+		Code:    code,
+		cause:   cause,
+		stack:   callers(3 + depth),
 	}
 }
 
 func (ae *AppError) FromString(err string, code int) {
-	ae.error = fmt.Errorf(err)
+	ae.Message = err
 	ae.Code = code
+	ae.cause = nil
+	ae.stack = callers(3)
 }
 
 func (ae *AppError) FromError(err error, code int) {
-	ae.error = err
+	ae.Message = ""
 	ae.Code = code
+	ae.cause = err
+	ae.stack = callers(3)
 }
 
 func (e *AppError) Error() string {
-	return e.error.Error()
+	switch {
+	case e.cause == nil:
+		return e.Message
+	case e.Message == "":
+		return e.cause.Error()
+	default:
+		return e.Message + ": " + e.cause.Error()
+	}
+}
+
+// Unwrap returns the error wrapped by the AppError, if any.
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// StackTrace returns the frames of the call stack recorded when the AppError was created.
+func (e *AppError) StackTrace() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	var result []runtime.Frame
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+
+	return result
+}
+
+// Stack returns the recorded call stack formatted one "function\n\tfile:line" entry per frame.
+func (e *AppError) Stack() string {
+	var sb strings.Builder
+	for _, frame := range e.StackTrace() {
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// Format implements fmt.Formatter. The %+v verb prints the error followed by its stack.
+func (e *AppError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%s\n%s", e.Error(), e.Stack())
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
 }
 
 func NewNotFoundError(message string) *AppError {
-	return NewAppError(message, 404)
+	return newAppError(message, 404, nil)
 }
 
 func NewBadRequestError(message string) *AppError {
-	return NewAppError(message, 400)
+	return newAppError(message, 400, nil)
 }
 
 func NewUnauthorizedError(message string) *AppError {
-	return NewAppError(message, 401)
+	return newAppError(message, 401, nil)
+}
+
+func NewForbiddenError(message string) *AppError {
+	return newAppError(message, 403, nil)
+}
+
+func NewConflictError(message string) *AppError {
+	return newAppError(message, 409, nil)
+}
+
+func NewGoneError(message string) *AppError {
+	return newAppError(message, 410, nil)
+}
+
+func NewPreconditionFailedError(message string) *AppError {
+	return newAppError(message, 412, nil)
+}
+
+func NewUnprocessableEntityError(message string) *AppError {
+	return newAppError(message, 422, nil)
+}
+
+// NewTooManyRequestsError creates a 429 AppError telling the client to retry after retryAfter.
+func NewTooManyRequestsError(message string, retryAfter time.Duration) *AppError {
+	appErr := newAppError(message, 429, nil)
+	appErr.RetryAfter = retryAfter
+
+	return appErr
 }
 
 func NewInternalServerError(message string) *AppError {
-	return NewAppError(message, 500)
+	return newAppError(message, 500, nil)
+}
+
+func NewBadGatewayError(message string) *AppError {
+	return newAppError(message, 502, nil)
+}
+
+func NewServiceUnavailableError(message string) *AppError {
+	return newAppError(message, 503, nil)
+}
+
+func NewGatewayTimeoutError(message string) *AppError {
+	return newAppError(message, 504, nil)
 }
 
-// GetRuntimeStack Returns the stack trace of the current goroutine
+// StatusCode returns the code of the first AppError in err's chain.
+// It returns false if the chain holds no AppError.
+func StatusCode(err error) (int, bool) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return 0, false
+	}
+
+	return appErr.Code, true
+}
+
+// RetryAfter returns the RetryAfter of the first AppError in err's chain, if it has one.
+func RetryAfter(err error) (time.Duration, bool) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	return appErr.RetryAfter, true
+}
+
+func IsBadRequest(err error) bool {
+	return hasStatus(err, 400)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, 401)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, 403)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, 404)
+}
+
+func IsConflict(err error) bool {
+	return hasStatus(err, 409)
+}
+
+func IsGone(err error) bool {
+	return hasStatus(err, 410)
+}
+
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, 412)
+}
+
+func IsUnprocessableEntity(err error) bool {
+	return hasStatus(err, 422)
+}
+
+func IsTooManyRequests(err error) bool {
+	return hasStatus(err, 429)
+}
+
+// IsClientError reports whether err's chain holds an AppError with a 4xx code.
+func IsClientError(err error) bool {
+	code, ok := StatusCode(err)
+	return ok && code >= 400 && code < 500
+}
+
+// IsServerError reports whether err's chain holds an AppError with a 5xx code.
+func IsServerError(err error) bool {
+	code, ok := StatusCode(err)
+	return ok && code >= 500 && code < 600
+}
+
+// IsRetryable reports whether the request that produced err may succeed if retried,
+// i.e. err's chain holds an AppError with a 408, 429, 502, 503 or 504 code.
+func IsRetryable(err error) bool {
+	code, ok := StatusCode(err)
+	if !ok {
+		return false
+	}
+
+	switch code {
+	case 408, 429, 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasStatus(err error, code int) bool {
+	actual, ok := StatusCode(err)
+	return ok && actual == code
+}
+
+// callers returns the program counters of the current goroutine's stack, skipping skip frames.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+
+	return pcs[:n]
+}
+
+// GetRuntimeStack Returns the full stack trace of the current goroutine.
+// The buffer is grown until the whole stack fits, so deep stacks are not truncated.
 func GetRuntimeStack() string {
 	stackBuf := make([]byte, 1024)
-	stackSize := runtime.Stack(stackBuf, false)
-	stackBuf = stackBuf[:stackSize]
+	for {
+		stackSize := runtime.Stack(stackBuf, false)
+		if stackSize < len(stackBuf) {
+			stackBuf = stackBuf[:stackSize]
+			break
+		}
+
+		stackBuf = make([]byte, 2*len(stackBuf))
+	}
+
 	return strings.TrimSpace(string(stackBuf))
 }
@@ -0,0 +1,191 @@
+package siocore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAppError(t *testing.T) {
+	err := NewAppError("100% broken", 418)
+
+	assert.Equal(t, "100% broken", err.Error())
+	assert.Equal(t, 418, err.Code)
+	assert.Nil(t, err.Unwrap())
+
+	frames := err.StackTrace()
+	assert.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "TestNewAppError"), frames[0].Function)
+}
+
+func TestAppError_ConstructorsStack(t *testing.T) {
+	tt := []struct {
+		name string
+		err  *AppError
+		code int
+	}{
+		{name: "not found", err: NewNotFoundError("nf"), code: 404},
+		{name: "bad request", err: NewBadRequestError("br"), code: 400},
+		{name: "unauthorized", err: NewUnauthorizedError("un"), code: 401},
+		{name: "internal", err: NewInternalServerError("is"), code: 500},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, tc.err.Code)
+			assert.True(
+				t,
+				strings.HasSuffix(tc.err.StackTrace()[0].Function, "TestAppError_ConstructorsStack"),
+				tc.err.StackTrace()[0].Function,
+			)
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF)
+
+	err := Wrap(cause, 502, "upstream failed")
+
+	assert.Equal(t, "upstream failed: reading body: unexpected EOF", err.Error())
+	assert.Equal(t, 502, err.Code)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Same(t, cause, errors.Unwrap(err))
+
+	outer := fmt.Errorf("handler: %w", err)
+	appErr := &AppError{}
+	assert.ErrorAs(t, outer, &appErr)
+	assert.Equal(t, 502, appErr.Code)
+
+	assert.Nil(t, Wrap(nil, 500, "nothing"))
+}
+
+func TestWrap_EmptyMessage(t *testing.T) {
+	err := Wrap(io.EOF, 500, "")
+
+	assert.Equal(t, io.EOF.Error(), err.Error())
+}
+
+func TestAppError_FromError(t *testing.T) {
+	ae := &AppError{}
+	ae.FromError(io.EOF, 400)
+
+	assert.Equal(t, io.EOF.Error(), ae.Error())
+	assert.ErrorIs(t, ae, io.EOF)
+	assert.NotEmpty(t, ae.StackTrace())
+
+	ae.FromString("bad %d", 404)
+	assert.Equal(t, "bad %d", ae.Error())
+	assert.Nil(t, ae.Unwrap())
+	assert.Equal(t, 404, ae.Code)
+}
+
+func TestAppError_Format(t *testing.T) {
+	err := NewBadRequestError("bad")
+
+	assert.Equal(t, "bad", fmt.Sprintf("%v", err))
+	assert.Equal(t, "bad", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"bad"`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, "bad\n"))
+	assert.Contains(t, verbose, "TestAppError_Format")
+	assert.Contains(t, verbose, "errors_test.go")
+}
+
+func TestGetRuntimeStack(t *testing.T) {
+	var deep func(n int) string
+	deep = func(n int) string {
+		if n == 0 {
+			return GetRuntimeStack()
+		}
+		return deep(n - 1)
+	}
+
+	stack := deep(100)
+
+	assert.Greater(t, len(stack), 1024)
+	assert.Contains(t, stack, "TestGetRuntimeStack")
+}
+
+func TestAppError_StatusConstructors(t *testing.T) {
+	tt := []struct {
+		name string
+		err  *AppError
+		code int
+	}{
+		{name: "forbidden", err: NewForbiddenError("m"), code: 403},
+		{name: "conflict", err: NewConflictError("m"), code: 409},
+		{name: "gone", err: NewGoneError("m"), code: 410},
+		{name: "precondition failed", err: NewPreconditionFailedError("m"), code: 412},
+		{name: "unprocessable entity", err: NewUnprocessableEntityError("m"), code: 422},
+		{name: "too many requests", err: NewTooManyRequestsError("m", time.Second), code: 429},
+		{name: "bad gateway", err: NewBadGatewayError("m"), code: 502},
+		{name: "service unavailable", err: NewServiceUnavailableError("m"), code: 503},
+		{name: "gateway timeout", err: NewGatewayTimeoutError("m"), code: 504},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, tc.err.Code)
+			assert.Equal(t, "m", tc.err.Error())
+			assert.True(
+				t,
+				strings.HasSuffix(tc.err.StackTrace()[0].Function, "TestAppError_StatusConstructors"),
+				tc.err.StackTrace()[0].Function,
+			)
+		})
+	}
+}
+
+func TestAppError_Predicates(t *testing.T) {
+	wrapped := func(err error) error {
+		return fmt.Errorf("service: %w", fmt.Errorf("repo: %w", err))
+	}
+
+	tt := []struct {
+		name      string
+		err       error
+		predicate func(error) bool
+		expected  bool
+	}{
+		{name: "not found", err: wrapped(NewNotFoundError("m")), predicate: IsNotFound, expected: true},
+		{name: "not found mismatch", err: wrapped(NewBadRequestError("m")), predicate: IsNotFound, expected: false},
+		{name: "bad request", err: wrapped(NewBadRequestError("m")), predicate: IsBadRequest, expected: true},
+		{name: "unauthorized", err: wrapped(NewUnauthorizedError("m")), predicate: IsUnauthorized, expected: true},
+		{name: "forbidden", err: wrapped(NewForbiddenError("m")), predicate: IsForbidden, expected: true},
+		{name: "conflict", err: wrapped(NewConflictError("m")), predicate: IsConflict, expected: true},
+		{name: "gone", err: wrapped(NewGoneError("m")), predicate: IsGone, expected: true},
+		{name: "precondition", err: wrapped(NewPreconditionFailedError("m")), predicate: IsPreconditionFailed, expected: true},
+		{name: "unprocessable", err: wrapped(NewUnprocessableEntityError("m")), predicate: IsUnprocessableEntity, expected: true},
+		{name: "too many", err: wrapped(NewTooManyRequestsError("m", 0)), predicate: IsTooManyRequests, expected: true},
+		{name: "client error", err: wrapped(NewConflictError("m")), predicate: IsClientError, expected: true},
+		{name: "client error on 5xx", err: wrapped(NewBadGatewayError("m")), predicate: IsClientError, expected: false},
+		{name: "server error", err: wrapped(NewGatewayTimeoutError("m")), predicate: IsServerError, expected: true},
+		{name: "retryable 429", err: wrapped(NewTooManyRequestsError("m", 0)), predicate: IsRetryable, expected: true},
+		{name: "retryable 503", err: wrapped(NewServiceUnavailableError("m")), predicate: IsRetryable, expected: true},
+		{name: "not retryable 500", err: wrapped(NewInternalServerError("m")), predicate: IsRetryable, expected: false},
+		{name: "plain error", err: io.EOF, predicate: IsServerError, expected: false},
+		{name: "nil error", err: nil, predicate: IsNotFound, expected: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.predicate(tc.err))
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	d, ok := RetryAfter(fmt.Errorf("wrapped: %w", NewTooManyRequestsError("slow down", 3*time.Second)))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	_, ok = RetryAfter(NewNotFoundError("m"))
+	assert.False(t, ok)
+}
@@ -3,22 +3,66 @@ package siocore
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const HeaderRetryAfter = "Retry-After"
+
+var ErrEmptyURL = errors.New("request url is empty")
+
 type RestHelpers struct {
 	client *http.Client
+	// logging and logOpts configure the logging transport, which is installed once all options are
+	// applied so the order of WithHTTPClient and WithRequestLogging does not matter.
+	logging bool
+	logOpts []RequestLogOption
+}
+
+// RestHelpersOption configures optional behaviour of RestHelpers.
+type RestHelpersOption func(*RestHelpers)
+
+// WithHTTPClient sets the http.Client used to execute requests. Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) RestHelpersOption {
+	return func(r *RestHelpers) {
+		r.client = client
+	}
+}
+
+// WithRequestLogging logs every outbound request and its response through the default slog logger.
+// The client's transport is wrapped in a copy, so a shared client such as http.DefaultClient is left untouched.
+func WithRequestLogging(opts ...RequestLogOption) RestHelpersOption {
+	return func(r *RestHelpers) {
+		r.logging = true
+		r.logOpts = append(r.logOpts, opts...)
+	}
 }
 
-func NewRestHelpers() *RestHelpers {
-	return &RestHelpers{
+func NewRestHelpers(opts ...RestHelpersOption) *RestHelpers {
+	r := &RestHelpers{
 		client: http.DefaultClient,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.client == nil {
+		r.client = http.DefaultClient
+	}
+	if r.logging {
+		client := *r.client
+		client.Transport = NewLoggingTransport(client.Transport, r.logOpts...)
+		r.client = &client
+	}
+
+	return r
 }
 
 func (r *RestHelpers) DoHttpRequest(req *http.Request) (*http.Response, error) {
@@ -85,7 +129,7 @@ func (r *RestHelpers) ParseResponse(resp *http.Response, v interface{}) error {
 }
 
 func (r *RestHelpers) ExecuteRequest(req *http.Request) (*http.Response, error) {
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +138,7 @@ func (r *RestHelpers) ExecuteRequest(req *http.Request) (*http.Response, error)
 }
 
 func (r *RestHelpers) PostForm(url string, values url.Values) (*http.Response, error) {
-	res, err := http.DefaultClient.PostForm(url, values)
+	res, err := r.client.PostForm(url, values)
 	if err != nil {
 		return nil, err
 	}
@@ -104,31 +148,60 @@ func (r *RestHelpers) PostForm(url string, values url.Values) (*http.Response, e
 
 func (r *RestHelpers) HandleResponse(resp *http.Response) (*http.Response, error) {
 	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
 			slog.Error(err.Error())
 		}
 
-		return nil, NewAppError(string(b), resp.StatusCode)
+		var appErr *AppError
+		if IsProblemResponse(resp) {
+			appErr, err = ParseProblemDetails(b, resp.StatusCode)
+			if err != nil {
+				slog.Error("unable to parse problem details", "error", err)
+			}
+		}
+		if appErr == nil {
+			appErr = NewAppError(string(b), resp.StatusCode)
+		}
+
+		appErr.RetryAfter = parseRetryAfter(resp.Header.Get(HeaderRetryAfter), time.Now())
+
+		return nil, appErr
 	}
 
 	return resp, nil
 }
 
-// func DecryptAndHandle(request interface{}, c *gin.Context) error {
-// 	enc := NewEncryptionUtil()
-// 	err := c.BindJSON(&request)
-// 	if err != nil {
-// 		return err
-// 	}
-//
-// 	err = enc.DecryptInterface(request)
-// 	if err != nil {
-// 		return err
-// 	}
-//
-// 	return nil
-// }
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+// It returns 0 when the header is missing, malformed or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// formatRetryAfter formats d as a Retry-After value in whole seconds, rounding up.
+func formatRetryAfter(d time.Duration) string {
+	seconds := (d + time.Second - 1) / time.Second
+
+	return strconv.FormatInt(int64(seconds), 10)
+}
 
 // BuildRequest creates a new http.Request with the given method, url and bodyReader. Also, adding the required headers.
 func BuildRequest(
@@ -137,7 +210,17 @@ func BuildRequest(
 	bodyReader *strings.Reader,
 	accessToken string,
 ) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, bodyReader)
+	if url == "" {
+		return nil, fmt.Errorf("error creating http request request: %w", ErrEmptyURL)
+	}
+
+	// a nil *strings.Reader must not reach http.NewRequest as a non-nil io.Reader
+	var body io.Reader
+	if bodyReader != nil {
+		body = bodyReader
+	}
+
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("error creating http request request: %w", err)
 	}
@@ -0,0 +1,246 @@
+package siocore
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxLoggedBodyBytes = 2048
+	RedactedValue             = "[REDACTED]"
+)
+
+// DefaultRedactedHeaders are the headers whose values are never written to the log.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// RequestLogOption configures the logging transport created by NewLoggingTransport.
+type RequestLogOption func(*requestLogConfig)
+
+type requestLogConfig struct {
+	maxBodyBytes  int
+	redactHeaders map[string]struct{}
+}
+
+// WithMaxLoggedBodyBytes sets how many bytes of the request and response bodies are logged.
+// A value of 0 disables body capture.
+func WithMaxLoggedBodyBytes(n int) RequestLogOption {
+	return func(c *requestLogConfig) {
+		c.maxBodyBytes = n
+	}
+}
+
+// WithRedactedHeaders adds headers whose values are replaced with RedactedValue in the log.
+func WithRedactedHeaders(headers ...string) RequestLogOption {
+	return func(c *requestLogConfig) {
+		for _, h := range headers {
+			c.redactHeaders[http.CanonicalHeaderKey(h)] = struct{}{}
+		}
+	}
+}
+
+type loggingTransport struct {
+	next http.RoundTripper
+	cfg  *requestLogConfig
+}
+
+// NewLoggingTransport wraps next so every round trip logs the method, url, status, duration,
+// headers and truncated bodies through the default slog logger. A nil next uses http.DefaultTransport.
+func NewLoggingTransport(next http.RoundTripper, opts ...RequestLogOption) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	cfg := &requestLogConfig{
+		maxBodyBytes:  DefaultMaxLoggedBodyBytes,
+		redactHeaders: make(map[string]struct{}),
+	}
+	WithRedactedHeaders(DefaultRedactedHeaders...)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &loggingTransport{next: next, cfg: cfg}
+}
+
+func (lt *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, reqBody, err := lt.captureRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := lt.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Duration("duration", duration),
+		slog.Any("request_headers", lt.redact(req.Header)),
+	}
+	if reqBody != "" {
+		attrs = append(attrs, slog.String("request_body", reqBody))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		slog.Default().LogAttrs(req.Context(), slog.LevelError, "outbound request failed", attrs...)
+
+		return nil, err
+	}
+
+	attrs = append(
+		attrs,
+		slog.Int("status", res.StatusCode),
+		slog.Any("response_headers", lt.redact(res.Header)),
+	)
+	if resBody := lt.captureResponseBody(res); resBody != "" {
+		attrs = append(attrs, slog.String("response_body", resBody))
+	}
+
+	slog.Default().LogAttrs(req.Context(), outboundLevel(res.StatusCode), "outbound request", attrs...)
+
+	return res, nil
+}
+
+// captureRequestBody returns the truncated request body along with the request to send.
+// Bodies without GetBody are only read up to the logged size and sent on through a clone of
+// req, as a RoundTripper must not modify the request it was given.
+func (lt *loggingTransport) captureRequestBody(req *http.Request) (*http.Request, string, error) {
+	if lt.cfg.maxBodyBytes <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, "", nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, "", err
+		}
+		defer body.Close()
+
+		b, err := io.ReadAll(io.LimitReader(body, int64(lt.cfg.maxBodyBytes)+1))
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, "", err
+		}
+
+		return req, lt.truncate(b), nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(req.Body, int64(lt.cfg.maxBodyBytes)+1))
+	if err != nil {
+		_ = req.Body.Close()
+		return nil, "", err
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(b), req.Body),
+		Closer: req.Body,
+	}
+
+	return clone, lt.truncate(b), nil
+}
+
+// captureResponseBody returns the truncated response body and replaces res.Body so the caller
+// still receives the full, unread body.
+// Bodies of unknown length and streaming content types are not captured, as reading them
+// would block RoundTrip until the server sends enough data or closes the stream.
+func (lt *loggingTransport) captureResponseBody(res *http.Response) string {
+	if lt.cfg.maxBodyBytes <= 0 || res.Body == nil || res.Body == http.NoBody {
+		return ""
+	}
+	if res.ContentLength < 0 || isStreamingContentType(res.Header.Get("Content-Type")) {
+		return ""
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, int64(lt.cfg.maxBodyBytes)+1))
+	res.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(b), res.Body),
+		Closer: res.Body,
+	}
+	if err != nil {
+		slog.Error("unable to capture response body", "error", err)
+	}
+
+	return lt.truncate(b)
+}
+
+// streamingContentTypes are the media types of responses that are consumed incrementally.
+var streamingContentTypes = map[string]struct{}{
+	"text/event-stream":         {},
+	"application/x-ndjson":      {},
+	"application/stream+json":   {},
+	"multipart/x-mixed-replace": {},
+	"application/grpc":          {},
+}
+
+func isStreamingContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	_, ok := streamingContentTypes[mediaType]
+
+	return ok
+}
+
+func (lt *loggingTransport) truncate(b []byte) string {
+	if len(b) > lt.cfg.maxBodyBytes {
+		return string(b[:lt.cfg.maxBodyBytes]) + "...(truncated)"
+	}
+
+	return string(b)
+}
+
+func (lt *loggingTransport) redact(headers http.Header) map[string]string {
+	result := make(map[string]string, len(headers))
+	for key, values := range headers {
+		if _, ok := lt.cfg.redactHeaders[http.CanonicalHeaderKey(key)]; ok {
+			result[key] = RedactedValue
+			continue
+		}
+
+		result[key] = strings.Join(values, ", ")
+	}
+
+	return result
+}
+
+// redactURL returns u with the password and the values of all query parameters replaced, as
+// they commonly carry tokens and keys.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for key := range query {
+			query[key] = []string{RedactedValue}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.Redacted()
+}
+
+func outboundLevel(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
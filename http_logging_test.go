@@ -0,0 +1,192 @@
+package siocore
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func captureDefaultLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+
+	return buf
+}
+
+func TestLoggingTransport(t *testing.T) {
+	buf := captureDefaultLogger(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"name":"sio"}`, string(body))
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(strings.Repeat("a", 20)))
+	}))
+	defer ts.Close()
+
+	rh := NewRestHelpers(WithRequestLogging(WithMaxLoggedBodyBytes(10), WithRedactedHeaders("X-Api-Key")))
+
+	req, err := BuildRequestWithBody(http.MethodPost, ts.URL, map[string]string{"name": "sio"}, "token")
+	assert.NoError(t, err)
+	req.Header.Set("X-Api-Key", "key")
+
+	_, err = rh.DoHttpRequest(req)
+	appErr := &AppError{}
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code)
+	assert.Equal(t, strings.Repeat("a", 20), appErr.Error(), "the full body must still reach the caller")
+
+	entry := map[string]any{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, http.MethodPost, entry["method"])
+	assert.Equal(t, ts.URL, entry["url"])
+	assert.Equal(t, float64(http.StatusBadRequest), entry["status"])
+	assert.Equal(t, `{"name":"s...(truncated)`, entry["request_body"])
+	assert.Equal(t, "aaaaaaaaaa...(truncated)", entry["response_body"])
+
+	reqHeaders := entry["request_headers"].(map[string]any)
+	assert.Equal(t, RedactedValue, reqHeaders["Authorization"])
+	assert.Equal(t, RedactedValue, reqHeaders["X-Api-Key"])
+	assert.Equal(t, "application/json", reqHeaders["Content-Type"])
+
+	resHeaders := entry["response_headers"].(map[string]any)
+	assert.Equal(t, RedactedValue, resHeaders["Set-Cookie"])
+}
+
+func TestLoggingTransport_Error(t *testing.T) {
+	buf := captureDefaultLogger(t)
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	assert.NoError(t, err)
+
+	rh := NewRestHelpers(
+		WithHTTPClient(&http.Client{Transport: &mockRoundTripper{expectedReq: req, err: io.ErrUnexpectedEOF}}),
+		WithRequestLogging(),
+	)
+
+	_, err = rh.ExecuteRequest(req)
+	assert.Error(t, err)
+
+	entry := map[string]any{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "outbound request failed", entry["msg"])
+	assert.Contains(t, entry["error"], io.ErrUnexpectedEOF.Error())
+}
+
+func TestWithRequestLogging_DoesNotMutateClient(t *testing.T) {
+	client := &http.Client{}
+
+	rh := NewRestHelpers(WithHTTPClient(client), WithRequestLogging())
+
+	assert.Nil(t, client.Transport)
+	assert.NotSame(t, client, rh.client)
+}
+
+func TestWithRequestLogging_OptionOrder(t *testing.T) {
+	client := &http.Client{}
+
+	rh := NewRestHelpers(WithRequestLogging(), WithHTTPClient(client))
+	_, ok := rh.client.Transport.(*loggingTransport)
+	assert.True(t, ok, "logging applies when passed before WithHTTPClient")
+
+	rh = NewRestHelpers(WithHTTPClient(nil), WithRequestLogging())
+	_, ok = rh.client.Transport.(*loggingTransport)
+	assert.True(t, ok, "a nil client falls back to http.DefaultClient")
+	assert.Nil(t, http.DefaultClient.Transport)
+}
+
+func TestLoggingTransport_StreamedBody(t *testing.T) {
+	buf := captureDefaultLogger(t)
+	payload := strings.Repeat("b", 100)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, string(body), "the full body is sent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	body := &readCloser{Reader: strings.NewReader(payload), Closer: io.NopCloser(nil)}
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/path?token=abc&page=2", body)
+	assert.NoError(t, err)
+	assert.Nil(t, req.GetBody)
+
+	rh := NewRestHelpers(WithRequestLogging(WithMaxLoggedBodyBytes(10)))
+	res, err := rh.ExecuteRequest(req)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	assert.True(t, req.Body == io.ReadCloser(body), "the caller's request is not modified")
+
+	entry := map[string]any{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "bbbbbbbbbb...(truncated)", entry["request_body"])
+	assert.Equal(t, ts.URL+"/path?page=%5BREDACTED%5D&token=%5BREDACTED%5D", entry["url"])
+}
+
+func TestLoggingTransport_StreamingResponse(t *testing.T) {
+	tt := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "event stream", contentType: "text/event-stream; charset=utf-8"},
+		{name: "unknown length", contentType: "application/json"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := captureDefaultLogger(t)
+			release := make(chan struct{})
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				_, _ = w.Write([]byte("data: first\n\n"))
+				w.(http.Flusher).Flush()
+				<-release
+			}))
+			defer ts.Close()
+			defer close(release)
+
+			req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+			assert.NoError(t, err)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				rh := NewRestHelpers(WithRequestLogging())
+				res, err := rh.ExecuteRequest(req)
+				if assert.NoError(t, err) {
+					_ = res.Body.Close()
+				}
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the request blocked on the streaming response body")
+			}
+
+			entry := map[string]any{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.NotContains(t, entry, "response_body")
+		})
+	}
+}
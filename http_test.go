@@ -70,10 +70,7 @@ func TestDoHttpRequest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	expectedRespBody, err := io.ReadAll(expectedResponse.Body)
-	if err != nil {
-		t.Fatal(err)
-	}
+	expectedRespBody := []byte(`{"status":"ok"}`)
 
 	if !bytes.Equal(respBody, expectedRespBody) {
 		t.Errorf("unexpected response body: %s", respBody)
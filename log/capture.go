@@ -0,0 +1,215 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CapturedEntry is a single log record recorded by a CaptureHandler.
+// Attributes inside groups are keyed by their dotted path, e.g. "request.method".
+type CapturedEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// Attr returns the value of the attribute with the given key.
+func (ce CapturedEntry) Attr(key string) (any, bool) {
+	v, ok := ce.Attrs[key]
+	return v, ok
+}
+
+type captureStore struct {
+	mu      sync.Mutex
+	entries []CapturedEntry
+}
+
+// CaptureHandler is an in-memory slog.Handler intended for asserting on log output in tests.
+// Handlers derived through WithAttrs and WithGroup record into the same store.
+type CaptureHandler struct {
+	store  *captureStore
+	level  slog.Leveler
+	attrs  map[string]any
+	groups []string
+}
+
+// NewCaptureHandler creates a CaptureHandler recording every record at or above level.
+// A nil level records everything.
+func NewCaptureHandler(level slog.Leveler) *CaptureHandler {
+	if level == nil {
+		level = slog.Level(-8)
+	}
+
+	return &CaptureHandler{
+		store: &captureStore{},
+		level: level,
+		attrs: map[string]any{},
+	}
+}
+
+// NewCapture creates an AppLogger backed by a new CaptureHandler, returning both.
+func NewCapture() (*AppLogger, *CaptureHandler) {
+	handler := NewCaptureHandler(nil)
+
+	return NewWithHandler(handler), handler
+}
+
+func (ch *CaptureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= ch.level.Level()
+}
+
+func (ch *CaptureHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := make(map[string]any, len(ch.attrs)+record.NumAttrs())
+	for k, v := range ch.attrs {
+		attrs[k] = v
+	}
+
+	prefix := ch.prefix()
+	record.Attrs(func(attr slog.Attr) bool {
+		flattenAttr(attrs, prefix, attr)
+		return true
+	})
+
+	ch.store.mu.Lock()
+	defer ch.store.mu.Unlock()
+
+	ch.store.entries = append(ch.store.entries, CapturedEntry{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+
+	return nil
+}
+
+func (ch *CaptureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := ch.clone()
+
+	prefix := ch.prefix()
+	for _, attr := range attrs {
+		flattenAttr(clone.attrs, prefix, attr)
+	}
+
+	return clone
+}
+
+func (ch *CaptureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return ch
+	}
+
+	clone := ch.clone()
+	clone.groups = append(clone.groups, name)
+
+	return clone
+}
+
+// Entries returns a copy of every recorded entry in the order they were logged.
+func (ch *CaptureHandler) Entries() []CapturedEntry {
+	ch.store.mu.Lock()
+	defer ch.store.mu.Unlock()
+
+	entries := make([]CapturedEntry, len(ch.store.entries))
+	copy(entries, ch.store.entries)
+
+	return entries
+}
+
+// Reset discards every recorded entry.
+func (ch *CaptureHandler) Reset() {
+	ch.store.mu.Lock()
+	defer ch.store.mu.Unlock()
+
+	ch.store.entries = nil
+}
+
+// ByLevel returns the entries logged at exactly the given level.
+func (ch *CaptureHandler) ByLevel(level slog.Level) []CapturedEntry {
+	return ch.filter(func(e CapturedEntry) bool {
+		return e.Level == level
+	})
+}
+
+// ByMessage returns the entries whose message equals msg.
+func (ch *CaptureHandler) ByMessage(msg string) []CapturedEntry {
+	return ch.filter(func(e CapturedEntry) bool {
+		return e.Message == msg
+	})
+}
+
+// ByAttr returns the entries with an attribute key whose value equals value.
+func (ch *CaptureHandler) ByAttr(key string, value any) []CapturedEntry {
+	return ch.filter(func(e CapturedEntry) bool {
+		v, ok := e.Attrs[key]
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// HasMessage reports whether an entry with the given level and message was recorded.
+func (ch *CaptureHandler) HasMessage(level slog.Level, msg string) bool {
+	return len(ch.filter(func(e CapturedEntry) bool {
+		return e.Level == level && e.Message == msg
+	})) > 0
+}
+
+func (ch *CaptureHandler) filter(keep func(CapturedEntry) bool) []CapturedEntry {
+	var result []CapturedEntry
+	for _, e := range ch.Entries() {
+		if keep(e) {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+func (ch *CaptureHandler) clone() *CaptureHandler {
+	attrs := make(map[string]any, len(ch.attrs))
+	for k, v := range ch.attrs {
+		attrs[k] = v
+	}
+
+	return &CaptureHandler{
+		store:  ch.store,
+		level:  ch.level,
+		attrs:  attrs,
+		groups: append([]string(nil), ch.groups...),
+	}
+}
+
+func (ch *CaptureHandler) prefix() string {
+	if len(ch.groups) == 0 {
+		return ""
+	}
+
+	return strings.Join(ch.groups, ".") + "."
+}
+
+// flattenAttr stores attr in attrs under its dotted key, expanding group values.
+func flattenAttr(attrs map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+
+		for _, ga := range attr.Value.Group() {
+			flattenAttr(attrs, groupPrefix, ga)
+		}
+
+		return
+	}
+
+	attrs[prefix+attr.Key] = attr.Value.Any()
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureHandler(t *testing.T) {
+	al, capture := NewCapture()
+	logger := al.Logger()
+
+	logger.Info("first", "user", "sio")
+	logger.With("env", "test").WithGroup("request").Warn("second", "method", "GET", slog.Group("client", "ip", "1.1.1.1"))
+	logger.Debug("third")
+
+	entries := capture.Entries()
+	assert.Len(t, entries, 3)
+
+	assert.Equal(t, "first", entries[0].Message)
+	assert.Equal(t, slog.LevelInfo, entries[0].Level)
+	assert.Equal(t, "sio", entries[0].Attrs["user"])
+
+	second := entries[1]
+	assert.Equal(t, "test", second.Attrs["env"])
+	assert.Equal(t, "GET", second.Attrs["request.method"])
+	ip, ok := second.Attr("request.client.ip")
+	assert.True(t, ok)
+	assert.Equal(t, "1.1.1.1", ip)
+
+	assert.Len(t, capture.ByLevel(slog.LevelWarn), 1)
+	assert.Len(t, capture.ByMessage("third"), 1)
+	assert.Len(t, capture.ByAttr("user", "sio"), 1)
+	assert.Empty(t, capture.ByAttr("user", "other"))
+	assert.True(t, capture.HasMessage(slog.LevelDebug, "third"))
+	assert.False(t, capture.HasMessage(slog.LevelError, "third"))
+
+	capture.Reset()
+	assert.Empty(t, capture.Entries())
+}
+
+func TestCaptureHandler_Level(t *testing.T) {
+	capture := NewCaptureHandler(slog.LevelWarn)
+	logger := NewWithHandler(capture).Logger()
+
+	logger.Info("dropped")
+	logger.Error("kept", "code", 500)
+
+	entries := capture.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "kept", entries[0].Message)
+	assert.Equal(t, int64(500), entries[0].Attrs["code"])
+}
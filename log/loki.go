@@ -30,6 +30,11 @@ func NewSlog(env siocore.Env) *AppLogger {
 	return &AppLogger{logger, client}
 }
 
+// NewWithHandler creates an AppLogger that writes to the given handler instead of Loki.
+func NewWithHandler(handler slog.Handler) *AppLogger {
+	return &AppLogger{logger: slog.New(handler)}
+}
+
 func (al *AppLogger) Logger() *slog.Logger {
 	return al.logger
 }
@@ -0,0 +1,227 @@
+package log
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderRequestID     = "X-Request-ID"
+	HeaderForwardedFor  = "X-Forwarded-For"
+	HeaderRealIP        = "X-Real-IP"
+	DefaultAccessLogMsg = "http request"
+)
+
+// LevelFunc maps a response status code to the level it should be logged at.
+type LevelFunc func(status int) slog.Level
+
+// AccessLogOption configures the AccessLog middleware.
+type AccessLogOption func(*accessLogConfig)
+
+type accessLogConfig struct {
+	message      string
+	skipPaths    map[string]struct{}
+	skipStatuses map[int]struct{}
+	levelFunc    LevelFunc
+	trustProxy   bool
+}
+
+// WithSkipPaths excludes requests for the given paths (e.g. "/health") from the access log.
+func WithSkipPaths(paths ...string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		for _, p := range paths {
+			c.skipPaths[p] = struct{}{}
+		}
+	}
+}
+
+// WithSkipStatuses excludes responses with the given status codes from the access log.
+func WithSkipStatuses(statuses ...int) AccessLogOption {
+	return func(c *accessLogConfig) {
+		for _, s := range statuses {
+			c.skipStatuses[s] = struct{}{}
+		}
+	}
+}
+
+// WithLevelFunc overrides the default status to level mapping.
+func WithLevelFunc(fn LevelFunc) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.levelFunc = fn
+	}
+}
+
+// WithTrustedProxyHeaders logs the client ip from the X-Forwarded-For and X-Real-IP headers,
+// see ForwardedIP. Only use it behind a proxy that sets these headers, as clients can send
+// them to log any ip they like.
+func WithTrustedProxyHeaders() AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.trustProxy = true
+	}
+}
+
+// WithAccessLogMessage overrides the message used for access log entries.
+func WithAccessLogMessage(msg string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.message = msg
+	}
+}
+
+// DefaultLevelFunc logs 5xx responses at error, 4xx at warn and everything else at info.
+func DefaultLevelFunc(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// AccessLog returns net/http middleware that logs every request through the AppLogger.
+// Each entry contains the method, path, status, latency, bytes written, remote ip,
+// user agent and request id. The remote ip is the connection address unless
+// WithTrustedProxyHeaders is set.
+func AccessLog(al *AppLogger, opts ...AccessLogOption) func(http.Handler) http.Handler {
+	cfg := &accessLogConfig{
+		message:      DefaultAccessLogMsg,
+		skipPaths:    make(map[string]struct{}),
+		skipStatuses: make(map[int]struct{}),
+		levelFunc:    DefaultLevelFunc,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, skip := cfg.skipPaths[r.URL.Path]; skip {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rw := newResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.Status()
+			if _, skip := cfg.skipStatuses[status]; skip {
+				return
+			}
+
+			remoteIP := RemoteIP(r)
+			if cfg.trustProxy {
+				remoteIP = ForwardedIP(r)
+			}
+
+			al.logger.LogAttrs(
+				r.Context(),
+				cfg.levelFunc(status),
+				cfg.message,
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int64("bytes", rw.BytesWritten()),
+				slog.String("remote_ip", remoteIP),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("request_id", RequestID(r)),
+			)
+		})
+	}
+}
+
+// RequestID returns the request id of the request, taken from the X-Request-ID header.
+func RequestID(r *http.Request) string {
+	return r.Header.Get(HeaderRequestID)
+}
+
+// ForwardedIP returns the client ip reported by a proxy in the X-Forwarded-For or X-Real-IP
+// header, falling back to RemoteIP. The headers are sent by the client unless a proxy
+// overwrites them, so the result can be spoofed when the service is reachable directly.
+func ForwardedIP(r *http.Request) string {
+	if fwd := r.Header.Get(HeaderForwardedFor); fwd != "" {
+		ip, _, _ := strings.Cut(fwd, ",")
+		return strings.TrimSpace(ip)
+	}
+
+	if ip := r.Header.Get(HeaderRealIP); ip != "" {
+		return ip
+	}
+
+	return RemoteIP(r)
+}
+
+// RemoteIP returns the ip of the connection the request arrived on.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// responseWriter wraps an http.ResponseWriter to record the status code and bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.wroteHeader {
+		return
+	}
+
+	rw.status = status
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+
+	return n, err
+}
+
+// Status returns the status code written to the response, defaulting to 200.
+func (rw *responseWriter) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
+
+// BytesWritten returns the number of body bytes written to the response.
+func (rw *responseWriter) BytesWritten() int64 {
+	return rw.bytes
+}
+
+// Flush implements http.Flusher when the underlying writer supports it.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,187 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newJSONLogger(t *testing.T) (*AppLogger, *bytes.Buffer) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return &AppLogger{logger: logger}, buf
+}
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var entries []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		entry := map[string]any{}
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestAccessLog(t *testing.T) {
+	al, buf := newJSONLogger(t)
+
+	handler := AccessLog(al)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	req.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := decodeEntries(t, buf)
+	assert.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, DefaultAccessLogMsg, entry["msg"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, http.MethodPost, entry["method"])
+	assert.Equal(t, "/users", entry["path"])
+	assert.Equal(t, float64(http.StatusCreated), entry["status"])
+	assert.Equal(t, float64(5), entry["bytes"])
+	assert.Equal(t, "192.0.2.1", entry["remote_ip"])
+	assert.Equal(t, "test-agent", entry["user_agent"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Contains(t, entry, "latency")
+}
+
+func TestAccessLog_Levels(t *testing.T) {
+	tt := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{name: "ok", status: http.StatusOK, expected: "INFO"},
+		{name: "client error", status: http.StatusNotFound, expected: "WARN"},
+		{name: "server error", status: http.StatusBadGateway, expected: "ERROR"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			al, buf := newJSONLogger(t)
+			handler := AccessLog(al)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := decodeEntries(t, buf)
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tc.expected, entries[0]["level"])
+		})
+	}
+}
+
+func TestAccessLog_Skip(t *testing.T) {
+	al, buf := newJSONLogger(t)
+
+	handler := AccessLog(
+		al,
+		WithSkipPaths("/health"),
+		WithSkipStatuses(http.StatusNotModified),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cached", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/logged", nil))
+
+	entries := decodeEntries(t, buf)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "/logged", entries[0]["path"])
+}
+
+func TestAccessLog_LevelFunc(t *testing.T) {
+	al, buf := newJSONLogger(t)
+
+	handler := AccessLog(al, WithLevelFunc(func(int) slog.Level {
+		return slog.LevelDebug
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entries := decodeEntries(t, buf)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "DEBUG", entries[0]["level"])
+	assert.Equal(t, float64(http.StatusOK), entries[0]["status"])
+}
+
+func TestRemoteIP(t *testing.T) {
+	tt := []struct {
+		name     string
+		headers  map[string]string
+		remote   string
+		expected string
+	}{
+		{name: "forwarded for", headers: map[string]string{HeaderForwardedFor: "1.1.1.1, 2.2.2.2"}, expected: "1.1.1.1"},
+		{name: "real ip", headers: map[string]string{HeaderRealIP: "3.3.3.3"}, expected: "3.3.3.3"},
+		{name: "remote addr", remote: "4.4.4.4:1234", expected: "4.4.4.4"},
+		{name: "remote addr without port", remote: "5.5.5.5", expected: "5.5.5.5"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			if tc.remote != "" {
+				req.RemoteAddr = tc.remote
+			}
+
+			assert.Equal(t, tc.expected, ForwardedIP(req))
+			if len(tc.headers) > 0 {
+				assert.Equal(t, "192.0.2.1", RemoteIP(req), "headers are ignored by RemoteIP")
+			}
+		})
+	}
+}
+
+func TestAccessLog_TrustedProxyHeaders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, tc := range []struct {
+		name     string
+		opts     []AccessLogOption
+		expected string
+	}{
+		{name: "untrusted by default", expected: "192.0.2.1"},
+		{name: "trusted", opts: []AccessLogOption{WithTrustedProxyHeaders()}, expected: "1.1.1.1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			al, buf := newJSONLogger(t)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(HeaderForwardedFor, "1.1.1.1")
+
+			AccessLog(al, tc.opts...)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := decodeEntries(t, buf)
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, tc.expected, entries[0]["remote_ip"])
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/slausonio/siocore"
+	"github.com/slausonio/siocore/metrics"
+)
+
+const PanicRecoveredMsg = "panic recovered"
+
+// Recoverer returns net/http middleware that recovers panics raised by the next handler.
+// The panic value and the full goroutine stack are logged with the request context,
+// metrics.HttpPanicsTotal is incremented, the panic is passed to siocore.ReportPanic and
+// a 500 AppError is written to the client as problem details.
+// http.ErrAbortHandler is re-raised so net/http can abort the response as intended.
+func Recoverer(al *AppLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := newResponseWriter(w)
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				stack := siocore.GetRuntimeStack()
+				al.logger.LogAttrs(
+					r.Context(),
+					slog.LevelError,
+					PanicRecoveredMsg,
+					slog.String("panic", fmt.Sprint(rec)),
+					slog.String("stack", stack),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_ip", RemoteIP(r)),
+					slog.String("request_id", RequestID(r)),
+				)
+
+				metrics.HttpPanicsTotal.WithLabelValues(r.Method).Inc()
+				siocore.ReportPanic(r.Context(), rec, stack)
+
+				if rw.wroteHeader {
+					return
+				}
+
+				// the panic was reported above, WriteProblem avoids WriteError reporting it again
+				appErr := siocore.NewInternalServerError(http.StatusText(http.StatusInternalServerError))
+				siocore.WriteProblem(rw, appErr.ProblemDetails())
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
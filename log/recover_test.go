@@ -0,0 +1,80 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/slausonio/siocore"
+	"github.com/slausonio/siocore/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverer(t *testing.T) {
+	al, capture := NewCapture()
+
+	var reports []siocore.ErrorReport
+	unregister := siocore.RegisterErrorReporter(siocore.ErrorReporterFunc(func(ctx context.Context, report siocore.ErrorReport) {
+		reports = append(reports, report)
+	}))
+	defer unregister()
+
+	handler := Recoverer(al)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	before := testutil.ToFloat64(metrics.HttpPanicsTotal.WithLabelValues(http.MethodGet))
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, siocore.ContentTypeProblemJSON, rec.Header().Get("Content-Type"))
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.HttpPanicsTotal.WithLabelValues(http.MethodGet)))
+
+	entries := capture.ByMessage(PanicRecoveredMsg)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, slog.LevelError, entries[0].Level)
+	assert.Equal(t, "boom", entries[0].Attrs["panic"])
+	assert.Equal(t, "req-1", entries[0].Attrs["request_id"])
+	assert.Contains(t, entries[0].Attrs["stack"], "TestRecoverer")
+
+	if assert.Len(t, reports, 1, "the panic must be reported exactly once") {
+		assert.True(t, reports[0].Panic)
+		assert.Equal(t, "boom", reports[0].Message)
+		assert.Contains(t, reports[0].Stack, "TestRecoverer")
+	}
+}
+
+func TestRecoverer_HeaderAlreadyWritten(t *testing.T) {
+	al, _ := NewCapture()
+
+	handler := Recoverer(al)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestRecoverer_AbortHandler(t *testing.T) {
+	al, capture := NewCapture()
+
+	handler := Recoverer(al)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Empty(t, capture.Entries())
+}
@@ -31,12 +31,22 @@ var (
 		},
 		[]string{"method", "path", "status_code"},
 	)
+
+	HttpPanicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_panics_total",
+			Help: "Total number of panics recovered while serving HTTP requests",
+		},
+		// no path label, raw request paths would make its cardinality unbounded
+		[]string{"method"},
+	)
 )
 
 func InitPrometheus() {
 	prometheus.MustRegister(HttpRequestDuration)
 	prometheus.MustRegister(HttpRequestsTotal)
 	prometheus.MustRegister(HttpRequestFailures)
+	prometheus.MustRegister(HttpPanicsTotal)
 	http.Handle("/metrics", promhttp.Handler())
 	if err := http.ListenAndServe(":2112", nil); err != nil {
 		panic(err)
@@ -0,0 +1,217 @@
+package siocore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+const (
+	ContentTypeProblemJSON = "application/problem+json"
+	DefaultProblemType     = "about:blank"
+
+	problemCodeMember = "code"
+)
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// ProblemDetails is an RFC 7807 problem details object. Extensions are serialized as
+// additional top level members next to the standard ones.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+func (pd ProblemDetails) MarshalJSON() ([]byte, error) {
+	result := make(map[string]any, len(pd.Extensions)+len(problemMembers))
+	for key, value := range pd.Extensions {
+		result[key] = value
+	}
+
+	problemType := pd.Type
+	if problemType == "" {
+		problemType = DefaultProblemType
+	}
+
+	result["type"] = problemType
+	result["status"] = pd.Status
+	if pd.Title != "" {
+		result["title"] = pd.Title
+	}
+	if pd.Detail != "" {
+		result["detail"] = pd.Detail
+	}
+	if pd.Instance != "" {
+		result["instance"] = pd.Instance
+	}
+
+	return json.Marshal(result)
+}
+
+func (pd *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var standard struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, key := range problemMembers {
+		delete(members, key)
+	}
+
+	pd.Type = standard.Type
+	pd.Title = standard.Title
+	pd.Status = standard.Status
+	pd.Detail = standard.Detail
+	pd.Instance = standard.Instance
+	pd.Extensions = nil
+	if len(members) > 0 {
+		pd.Extensions = members
+	}
+
+	return nil
+}
+
+// ProblemDetails renders the AppError as a problem details object. The detail member only
+// contains the AppError message, never the text of a wrapped cause, so internal errors
+// are not exposed to clients.
+func (e *AppError) ProblemDetails() *ProblemDetails {
+	status := e.Code
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	detail := e.Message
+	if detail == "" && status < http.StatusInternalServerError {
+		detail = http.StatusText(status)
+	}
+
+	extensions := e.Details
+	if e.ErrorCode != "" {
+		extensions = make(map[string]any, len(e.Details)+1)
+		for key, value := range e.Details {
+			extensions[key] = value
+		}
+		extensions[problemCodeMember] = e.ErrorCode
+	}
+
+	return &ProblemDetails{
+		Type:       e.Type,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     detail,
+		Instance:   e.Instance,
+		Extensions: extensions,
+	}
+}
+
+// AppError converts the problem details back into an AppError. The title is used as the
+// message when there is no detail and a "code" extension becomes the ErrorCode.
+func (pd *ProblemDetails) AppError() *AppError {
+	message := pd.Detail
+	if message == "" {
+		message = pd.Title
+	}
+
+	appErr := newAppError(message, pd.Status, nil)
+	if pd.Type != DefaultProblemType {
+		appErr.Type = pd.Type
+	}
+	appErr.Instance = pd.Instance
+
+	details := make(map[string]any, len(pd.Extensions))
+	for key, value := range pd.Extensions {
+		if code, ok := value.(string); ok && key == problemCodeMember {
+			appErr.ErrorCode = ErrorCode(code)
+			continue
+		}
+
+		details[key] = value
+	}
+	if len(details) > 0 {
+		appErr.Details = details
+	}
+
+	return appErr
+}
+
+// ParseProblemDetails parses an application/problem+json body into an AppError.
+// status is used when the body does not carry one, e.g. the status of the http.Response.
+func ParseProblemDetails(body []byte, status int) (*AppError, error) {
+	pd := &ProblemDetails{}
+	if err := json.Unmarshal(body, pd); err != nil {
+		return nil, err
+	}
+
+	if pd.Status == 0 {
+		pd.Status = status
+	}
+
+	return pd.AppError(), nil
+}
+
+// IsProblemResponse reports whether the response carries an application/problem+json body.
+func IsProblemResponse(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == ContentTypeProblemJSON
+}
+
+// WriteProblem writes the problem details as an application/problem+json response. A status
+// that is not a client or server error is written as 500.
+func WriteProblem(w http.ResponseWriter, pd *ProblemDetails) {
+	if pd.Status < http.StatusBadRequest || pd.Status > 599 {
+		fixed := *pd
+		fixed.Status = http.StatusInternalServerError
+		fixed.Title = http.StatusText(http.StatusInternalServerError)
+		pd = &fixed
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
+	w.WriteHeader(pd.Status)
+
+	if err := json.NewEncoder(w).Encode(pd); err != nil {
+		slog.Error("unable to write problem details", "error", err)
+	}
+}
+
+// WriteError writes err as an application/problem+json response. AppErrors anywhere in
+// the chain are rendered with their own status and Retry-After; any other error becomes
+// a generic 500 so internal details do not leak to the client. Server errors are passed to
+// ReportError. Handlers should prefer WriteErrorContext so reporters see the request context.
+func WriteError(w http.ResponseWriter, err error) {
+	WriteErrorContext(context.Background(), w, err)
+}
+
+// WriteErrorContext is WriteError passing ctx, usually the request's context, to ReportError.
+func WriteErrorContext(ctx context.Context, w http.ResponseWriter, err error) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		appErr = newAppError("", http.StatusInternalServerError, err)
+	}
+
+	if appErr.Code >= http.StatusInternalServerError {
+		ReportError(ctx, appErr)
+	}
+
+	if appErr.RetryAfter > 0 {
+		w.Header().Set(HeaderRetryAfter, formatRetryAfter(appErr.RetryAfter))
+	}
+
+	WriteProblem(w, appErr.ProblemDetails())
+}
@@ -0,0 +1,229 @@
+package siocore
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemDetails_MarshalJSON(t *testing.T) {
+	pd := ProblemDetails{
+		Title:      "Not Found",
+		Status:     404,
+		Detail:     "user 1 not found",
+		Instance:   "/users/1",
+		Extensions: map[string]any{"traceId": "abc", "status": "ignored"},
+	}
+
+	b, err := json.Marshal(pd)
+	assert.NoError(t, err)
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, map[string]any{
+		"type":     DefaultProblemType,
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "user 1 not found",
+		"instance": "/users/1",
+		"traceId":  "abc",
+	}, got)
+}
+
+func TestProblemDetails_UnmarshalJSON(t *testing.T) {
+	body := `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,` +
+		`"detail":"balance is 30","instance":"/account/1","balance":30}`
+
+	pd := &ProblemDetails{}
+	assert.NoError(t, json.Unmarshal([]byte(body), pd))
+
+	assert.Equal(t, "https://example.com/probs/out-of-credit", pd.Type)
+	assert.Equal(t, "Forbidden", pd.Title)
+	assert.Equal(t, 403, pd.Status)
+	assert.Equal(t, "balance is 30", pd.Detail)
+	assert.Equal(t, "/account/1", pd.Instance)
+	assert.Equal(t, map[string]any{"balance": float64(30)}, pd.Extensions)
+}
+
+func TestParseProblemDetails(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		original := NewNotFoundError("user not found")
+		original.Instance = "/users/1"
+		original.Details = map[string]any{"id": "1"}
+
+		b, err := json.Marshal(original.ProblemDetails())
+		assert.NoError(t, err)
+
+		parsed, err := ParseProblemDetails(b, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 404, parsed.Code)
+		assert.Equal(t, "user not found", parsed.Message)
+		assert.Equal(t, "", parsed.Type)
+		assert.Equal(t, "/users/1", parsed.Instance)
+		assert.Equal(t, map[string]any{"id": "1"}, parsed.Details)
+	})
+
+	t.Run("status and title fallback", func(t *testing.T) {
+		parsed, err := ParseProblemDetails([]byte(`{"title":"Bad Gateway"}`), 502)
+		assert.NoError(t, err)
+		assert.Equal(t, 502, parsed.Code)
+		assert.Equal(t, "Bad Gateway", parsed.Message)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := ParseProblemDetails([]byte(`not json`), 500)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	tt := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedDetail any
+	}{
+		{
+			name:           "app error",
+			err:            NewBadRequestError(InvalidEmailErr),
+			expectedStatus: 400,
+			expectedDetail: InvalidEmailErr,
+		},
+		{
+			name:           "wrapped app error",
+			err:            errors.Join(errors.New("other"), NewUnauthorizedError(TokenInvalid)),
+			expectedStatus: 401,
+			expectedDetail: TokenInvalid,
+		},
+		{
+			name:           "wrapped cause is not leaked",
+			err:            Wrap(errors.New("pq: password authentication failed"), 500, ""),
+			expectedStatus: 500,
+			expectedDetail: nil,
+		},
+		{
+			name:           "client error cause is not leaked",
+			err:            Wrap(errors.New("strconv.Atoi: parsing \"x\""), 400, ""),
+			expectedStatus: 400,
+			expectedDetail: http.StatusText(400),
+		},
+		{
+			name:           "invalid status",
+			err:            NewAppError("teapot", 1000),
+			expectedStatus: 500,
+			expectedDetail: "teapot",
+		},
+		{
+			name:           "unknown error",
+			err:            errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedStatus: 500,
+			expectedDetail: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteError(rec, tc.err)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, ContentTypeProblemJSON, rec.Header().Get("Content-Type"))
+
+			var body map[string]any
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, float64(tc.expectedStatus), body["status"])
+			assert.Equal(t, http.StatusText(tc.expectedStatus), body["title"])
+			assert.Equal(t, tc.expectedDetail, body["detail"])
+		})
+	}
+}
+
+func TestHandleResponse_Problem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := NewNotFoundError("widget not found")
+		err.Details = map[string]any{"widget": "w1"}
+		WriteError(w, err)
+	}))
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = NewRestHelpers().DoHttpRequest(req)
+
+	appErr := &AppError{}
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 404, appErr.Code)
+	assert.Equal(t, "widget not found", appErr.Error())
+	assert.Equal(t, map[string]any{"widget": "w1"}, appErr.Details)
+}
+
+func TestHandleResponse_PlainBody(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 503,
+		Header:     http.Header{"Content-Type": []string{ContentTypeProblemJSON}},
+		Body:       io.NopCloser(strings.NewReader("unavailable")),
+	}
+
+	_, err := NewRestHelpers().HandleResponse(resp)
+
+	appErr := &AppError{}
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 503, appErr.Code)
+	assert.Equal(t, "unavailable", appErr.Error())
+}
+
+func TestWriteError_RetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, NewTooManyRequestsError("slow down", 1500*time.Millisecond))
+
+	assert.Equal(t, 429, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderRetryAfter))
+}
+
+func TestHandleResponse_RetryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, NewTooManyRequestsError("slow down", 5*time.Second))
+	}))
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = NewRestHelpers().DoHttpRequest(req)
+
+	assert.True(t, IsTooManyRequests(err))
+	d, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, d)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "empty", value: "", expected: 0},
+		{name: "seconds", value: "120", expected: 2 * time.Minute},
+		{name: "negative", value: "-1", expected: 0},
+		{name: "date", value: now.Add(time.Minute).Format(http.TimeFormat), expected: time.Minute},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0},
+		{name: "malformed", value: "soon", expected: 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseRetryAfter(tc.value, now))
+		})
+	}
+}
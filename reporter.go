@@ -0,0 +1,140 @@
+package siocore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrorReport describes an internal error or recovered panic handed to the registered ErrorReporters.
+type ErrorReport struct {
+	// Fingerprint identifies reports of the same problem, see Fingerprint.
+	Fingerprint string    `json:"fingerprint"`
+	Message     string    `json:"message"`
+	Code        int       `json:"code"`
+	ErrorCode   ErrorCode `json:"errorCode,omitempty"`
+	Stack       string    `json:"stack,omitempty"`
+	Panic       bool      `json:"panic"`
+	Time        time.Time `json:"time"`
+	// Count is how many times the report occurred, used by reporters that deduplicate.
+	Count int `json:"count"`
+}
+
+// ErrorReporter receives reports of 5xx AppErrors and recovered panics.
+// Report is called synchronously and must not block.
+type ErrorReporter interface {
+	Report(ctx context.Context, report ErrorReport)
+}
+
+// ErrorReporterFunc adapts a function to an ErrorReporter.
+type ErrorReporterFunc func(ctx context.Context, report ErrorReport)
+
+func (f ErrorReporterFunc) Report(ctx context.Context, report ErrorReport) {
+	f(ctx, report)
+}
+
+var reporterRegistry = struct {
+	mu        sync.RWMutex
+	nextID    int
+	reporters map[int]ErrorReporter
+}{reporters: make(map[int]ErrorReporter)}
+
+// RegisterErrorReporter adds reporter to the reporters invoked by ReportError and ReportPanic.
+// The returned function removes it again.
+func RegisterErrorReporter(reporter ErrorReporter) (unregister func()) {
+	reporterRegistry.mu.Lock()
+	defer reporterRegistry.mu.Unlock()
+
+	id := reporterRegistry.nextID
+	reporterRegistry.nextID++
+	reporterRegistry.reporters[id] = reporter
+
+	return func() {
+		reporterRegistry.mu.Lock()
+		defer reporterRegistry.mu.Unlock()
+
+		delete(reporterRegistry.reporters, id)
+	}
+}
+
+// ReportError reports err to the registered reporters when it is a server error: either a 5xx
+// AppError in err's chain or an error without an AppError, which is answered with a 500.
+// Client errors and nil are ignored.
+func ReportError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		appErr = newAppError("", http.StatusInternalServerError, err)
+	}
+	if appErr.Code < http.StatusInternalServerError {
+		return
+	}
+
+	report := ErrorReport{
+		Message:   err.Error(),
+		Code:      appErr.Code,
+		ErrorCode: appErr.ErrorCode,
+		Stack:     appErr.Stack(),
+		Time:      time.Now(),
+		Count:     1,
+	}
+	report.Fingerprint = Fingerprint(report)
+
+	dispatchReport(ctx, report)
+}
+
+// ReportPanic reports a recovered panic value and the stack it was raised from to the registered reporters.
+func ReportPanic(ctx context.Context, recovered any, stack string) {
+	report := ErrorReport{
+		Message: fmt.Sprint(recovered),
+		Code:    http.StatusInternalServerError,
+		Stack:   stack,
+		Panic:   true,
+		Time:    time.Now(),
+		Count:   1,
+	}
+	report.Fingerprint = Fingerprint(report)
+
+	dispatchReport(ctx, report)
+}
+
+var (
+	goroutineIDRegex = regexp.MustCompile(`goroutine \d+`)
+	hexValueRegex    = regexp.MustCompile(`0x[0-9a-f]+`)
+)
+
+// Fingerprint returns a stable identifier for the report built from its code, error code,
+// message, whether it is a panic and its stack, so repeats of the same failure share it.
+// Goroutine ids and addresses are removed from the stack first as they differ between repeats.
+func Fingerprint(report ErrorReport) string {
+	stack := goroutineIDRegex.ReplaceAllString(report.Stack, "goroutine")
+	stack = hexValueRegex.ReplaceAllString(stack, "0x")
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%s|%t|%s", report.Code, report.ErrorCode, report.Message, report.Panic, stack)
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func dispatchReport(ctx context.Context, report ErrorReport) {
+	// reporters are called without the lock so they may register or unregister reporters
+	reporterRegistry.mu.RLock()
+	reporters := make([]ErrorReporter, 0, len(reporterRegistry.reporters))
+	for _, reporter := range reporterRegistry.reporters {
+		reporters = append(reporters, reporter)
+	}
+	reporterRegistry.mu.RUnlock()
+
+	for _, reporter := range reporters {
+		reporter.Report(ctx, report)
+	}
+}
@@ -0,0 +1,398 @@
+package siocore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectReports(t *testing.T) *[]ErrorReport {
+	t.Helper()
+
+	var mu sync.Mutex
+	reports := &[]ErrorReport{}
+	unregister := RegisterErrorReporter(ErrorReporterFunc(func(ctx context.Context, report ErrorReport) {
+		mu.Lock()
+		defer mu.Unlock()
+		*reports = append(*reports, report)
+	}))
+	t.Cleanup(unregister)
+
+	return reports
+}
+
+func TestReportError(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		reported bool
+		code     int
+	}{
+		{name: "nil", err: nil},
+		{name: "client error", err: NewNotFoundError("missing")},
+		{name: "server error", err: NewInternalServerError("db down"), reported: true, code: 500},
+		{name: "wrapped server error", err: errors.Join(errors.New("ctx"), NewBadGatewayError("upstream")), reported: true, code: 502},
+		{name: "plain error", err: errors.New("boom"), reported: true, code: 500},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			reports := collectReports(t)
+
+			ReportError(context.Background(), tc.err)
+
+			if !tc.reported {
+				assert.Empty(t, *reports)
+				return
+			}
+
+			if assert.Len(t, *reports, 1) {
+				report := (*reports)[0]
+				assert.Equal(t, tc.code, report.Code)
+				assert.Equal(t, tc.err.Error(), report.Message)
+				assert.NotEmpty(t, report.Stack)
+				assert.NotEmpty(t, report.Fingerprint)
+				assert.Equal(t, 1, report.Count)
+				assert.False(t, report.Panic)
+			}
+		})
+	}
+}
+
+func TestReportPanic(t *testing.T) {
+	reports := collectReports(t)
+
+	ReportPanic(context.Background(), "boom", "goroutine 7 [running]:\nmain.handler()")
+
+	if assert.Len(t, *reports, 1) {
+		assert.True(t, (*reports)[0].Panic)
+		assert.Equal(t, "boom", (*reports)[0].Message)
+		assert.Equal(t, http.StatusInternalServerError, (*reports)[0].Code)
+	}
+}
+
+func TestRegisterErrorReporter_Unregister(t *testing.T) {
+	var count int
+	unregister := RegisterErrorReporter(ErrorReporterFunc(func(ctx context.Context, report ErrorReport) {
+		count++
+	}))
+
+	ReportError(context.Background(), NewInternalServerError("one"))
+	unregister()
+	ReportError(context.Background(), NewInternalServerError("two"))
+
+	assert.Equal(t, 1, count)
+
+	t.Run("from a reporter", func(t *testing.T) {
+		var unregister func()
+		unregister = RegisterErrorReporter(ErrorReporterFunc(func(ctx context.Context, report ErrorReport) {
+			unregister()
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ReportError(context.Background(), NewInternalServerError("three"))
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("unregistering from a reporter deadlocked")
+		}
+	})
+}
+
+func TestWriteError_Reports(t *testing.T) {
+	reports := collectReports(t)
+
+	WriteError(httptest.NewRecorder(), NewBadRequestError(InvalidEmailErr))
+	WriteError(httptest.NewRecorder(), NewServiceUnavailableError("maintenance"))
+
+	if assert.Len(t, *reports, 1) {
+		assert.Equal(t, http.StatusServiceUnavailable, (*reports)[0].Code)
+	}
+}
+
+func TestWriteErrorContext_Reports(t *testing.T) {
+	type ctxKey struct{}
+
+	var got context.Context
+	unregister := RegisterErrorReporter(ErrorReporterFunc(func(ctx context.Context, report ErrorReport) {
+		got = ctx
+	}))
+	defer unregister()
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	WriteErrorContext(ctx, httptest.NewRecorder(), errors.New("boom"))
+
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "request", got.Value(ctxKey{}))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := ErrorReport{
+		Message: "boom",
+		Code:    500,
+		Stack:   "goroutine 7 [running]:\nmain.handler(0xc000123456)\n\t/app/main.go:12 +0x1d",
+	}
+
+	sameProblem := base
+	sameProblem.Stack = "goroutine 42 [running]:\nmain.handler(0xc000abcdef)\n\t/app/main.go:12 +0x1d"
+
+	otherLine := base
+	otherLine.Stack = "goroutine 7 [running]:\nmain.handler(0xc000123456)\n\t/app/main.go:13 +0x1d"
+
+	otherMessage := base
+	otherMessage.Message = "bang"
+
+	assert.Len(t, Fingerprint(base), 16)
+	assert.Equal(t, Fingerprint(base), Fingerprint(sameProblem))
+	assert.NotEqual(t, Fingerprint(base), Fingerprint(otherLine))
+	assert.NotEqual(t, Fingerprint(base), Fingerprint(otherMessage))
+}
+
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []WebhookPayload
+	headers  []http.Header
+	received chan struct{}
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	t.Helper()
+
+	ws := &webhookServer{received: make(chan struct{}, 10)}
+	ws.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		ws.mu.Lock()
+		ws.payloads = append(ws.payloads, payload)
+		ws.headers = append(ws.headers, r.Header.Clone())
+		ws.mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+		ws.received <- struct{}{}
+	}))
+	t.Cleanup(ws.Close)
+
+	return ws
+}
+
+func (ws *webhookServer) Payloads() []WebhookPayload {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return append([]WebhookPayload(nil), ws.payloads...)
+}
+
+func TestWebhookReporter_DedupAndClose(t *testing.T) {
+	ws := newWebhookServer(t)
+
+	wr := NewWebhookReporter(ws.URL,
+		WithFlushInterval(time.Hour),
+		WithWebhookHeaders(map[string]string{"Authorization": "Bearer token"}),
+	)
+
+	wr.Report(context.Background(), ErrorReport{Message: "boom", Code: 500, Stack: "goroutine 1 [running]:"})
+	wr.Report(context.Background(), ErrorReport{Message: "boom", Code: 500, Stack: "goroutine 2 [running]:"})
+	wr.Report(context.Background(), ErrorReport{Message: "other", Code: 502})
+
+	assert.NoError(t, wr.Close(context.Background()))
+
+	payloads := ws.Payloads()
+	if assert.Len(t, payloads, 1) && assert.Len(t, payloads[0].Reports, 2) {
+		assert.Equal(t, "boom", payloads[0].Reports[0].Message)
+		assert.Equal(t, 2, payloads[0].Reports[0].Count)
+		assert.Equal(t, "other", payloads[0].Reports[1].Message)
+		assert.Equal(t, 1, payloads[0].Reports[1].Count)
+	}
+	assert.Equal(t, "Bearer token", ws.headers[0].Get("Authorization"))
+	assert.Equal(t, "application/json", ws.headers[0].Get("Content-Type"))
+}
+
+func TestWebhookReporter_DedupWindow(t *testing.T) {
+	ws := newWebhookServer(t)
+
+	wr := NewWebhookReporter(ws.URL, WithFlushInterval(time.Hour), WithDedupWindow(time.Hour))
+	defer wr.Close(context.Background())
+
+	report := ErrorReport{Message: "boom", Code: 500}
+
+	wr.Report(context.Background(), report)
+	assert.NoError(t, wr.Flush(context.Background()))
+
+	wr.Report(context.Background(), report)
+	wr.Report(context.Background(), ErrorReport{Message: "new", Code: 500})
+	assert.NoError(t, wr.Flush(context.Background()))
+
+	payloads := ws.Payloads()
+	if assert.Len(t, payloads, 2) && assert.Len(t, payloads[1].Reports, 1) {
+		assert.Equal(t, "new", payloads[1].Reports[0].Message, "repeats within the dedup window are dropped")
+	}
+}
+
+func TestWebhookReporter_BatchSize(t *testing.T) {
+	ws := newWebhookServer(t)
+
+	wr := NewWebhookReporter(ws.URL, WithFlushInterval(time.Hour), WithBatchSize(2))
+	defer wr.Close(context.Background())
+
+	wr.Report(context.Background(), ErrorReport{Message: "one", Code: 500})
+	wr.Report(context.Background(), ErrorReport{Message: "two", Code: 500})
+
+	select {
+	case <-ws.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch was not flushed when full")
+	}
+
+	payloads := ws.Payloads()
+	if assert.Len(t, payloads, 1) {
+		assert.Len(t, payloads[0].Reports, 2)
+	}
+}
+
+func TestWebhookReporter_FlushInterval(t *testing.T) {
+	ws := newWebhookServer(t)
+
+	wr := NewWebhookReporter(ws.URL, WithFlushInterval(10*time.Millisecond))
+	defer wr.Close(context.Background())
+
+	wr.Report(context.Background(), ErrorReport{Message: "one", Code: 500})
+
+	select {
+	case <-ws.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reports were not flushed on the interval")
+	}
+}
+
+func TestWebhookReporter_FlushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	wr := NewWebhookReporter(server.URL, WithFlushInterval(time.Hour))
+
+	wr.Report(context.Background(), ErrorReport{Message: "one", Code: 500})
+
+	assert.Error(t, wr.Close(context.Background()))
+}
+
+func TestWebhookReporter_RequeueOnFailure(t *testing.T) {
+	var mu sync.Mutex
+	fail := true
+	var payloads []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var payload WebhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	wr := NewWebhookReporter(server.URL, WithFlushInterval(time.Hour))
+	defer wr.Close(context.Background())
+
+	report := ErrorReport{Message: "boom", Code: 500}
+	wr.Report(context.Background(), report)
+	assert.Error(t, wr.Flush(context.Background()))
+
+	wr.Report(context.Background(), report)
+	wr.Report(context.Background(), ErrorReport{Message: "other", Code: 500})
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	assert.NoError(t, wr.Flush(context.Background()))
+
+	if assert.Len(t, payloads, 1) && assert.Len(t, payloads[0].Reports, 2) {
+		assert.Equal(t, "boom", payloads[0].Reports[0].Message)
+		assert.Equal(t, 2, payloads[0].Reports[0].Count, "the failed batch is merged with later reports")
+		assert.Equal(t, "other", payloads[0].Reports[1].Message)
+	}
+}
+
+func TestWebhookReporter_ReportAfterClose(t *testing.T) {
+	ws := newWebhookServer(t)
+
+	wr := NewWebhookReporter(ws.URL, WithFlushInterval(0))
+	assert.NoError(t, wr.Close(context.Background()))
+
+	wr.Report(context.Background(), ErrorReport{Message: "late", Code: 500})
+	assert.NoError(t, wr.Flush(context.Background()))
+	assert.Empty(t, ws.Payloads(), "reports after Close are dropped")
+}
+
+func TestWebhookReporter_CloseHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	wr := NewWebhookReporter(server.URL, WithFlushInterval(time.Hour), WithBatchSize(1))
+	wr.Report(context.Background(), ErrorReport{Message: "boom", Code: 500})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, wr.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "Close must not wait for the hanging endpoint")
+}
+
+func TestWebhookReporter_MaxPending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	wr := NewWebhookReporter(server.URL, WithFlushInterval(time.Hour), WithMaxPending(2))
+	defer wr.Close(context.Background())
+
+	pendingMessages := func() []string {
+		wr.mu.Lock()
+		defer wr.mu.Unlock()
+
+		var messages []string
+		for _, report := range wr.pending {
+			messages = append(messages, report.Message)
+		}
+		assert.Len(t, wr.byPrint, len(wr.pending))
+
+		return messages
+	}
+
+	wr.Report(context.Background(), ErrorReport{Message: "one", Code: 500})
+	wr.Report(context.Background(), ErrorReport{Message: "two", Code: 500})
+	wr.Report(context.Background(), ErrorReport{Message: "three", Code: 500})
+	assert.Equal(t, []string{"two", "three"}, pendingMessages(), "the oldest report is dropped")
+
+	assert.Error(t, wr.Flush(context.Background()))
+	wr.Report(context.Background(), ErrorReport{Message: "four", Code: 500})
+	assert.Equal(t, []string{"three", "four"}, pendingMessages(), "failed batches are capped too")
+}
@@ -0,0 +1,329 @@
+package siocore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWebhookBatchSize     = 50
+	DefaultWebhookFlushInterval = 10 * time.Second
+	DefaultWebhookDedupWindow   = time.Minute
+	DefaultWebhookTimeout       = 10 * time.Second
+	DefaultWebhookMaxPending    = 1000
+)
+
+// WebhookPayload is the JSON body posted by a WebhookReporter.
+type WebhookPayload struct {
+	Reports []ErrorReport `json:"reports"`
+}
+
+// WebhookOption configures a WebhookReporter.
+type WebhookOption func(*WebhookReporter)
+
+// WithWebhookClient sets the http.Client used to post batches. Defaults to a client with a
+// DefaultWebhookTimeout timeout.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(wr *WebhookReporter) {
+		wr.client = client
+	}
+}
+
+// WithWebhookHeaders adds headers, e.g. an Authorization header, to every posted batch.
+func WithWebhookHeaders(headers map[string]string) WebhookOption {
+	return func(wr *WebhookReporter) {
+		for key, value := range headers {
+			wr.headers.Set(key, value)
+		}
+	}
+}
+
+// WithBatchSize sets the number of distinct reports that triggers an immediate flush.
+func WithBatchSize(size int) WebhookOption {
+	return func(wr *WebhookReporter) {
+		wr.batchSize = size
+	}
+}
+
+// WithFlushInterval sets how often pending reports are posted. Intervals of zero or less
+// keep DefaultWebhookFlushInterval.
+func WithFlushInterval(interval time.Duration) WebhookOption {
+	return func(wr *WebhookReporter) {
+		if interval <= 0 {
+			interval = DefaultWebhookFlushInterval
+		}
+		wr.flushInterval = interval
+	}
+}
+
+// WithMaxPending sets how many distinct reports are kept pending. When the limit is reached the
+// oldest pending reports are dropped. Values of zero or less keep DefaultWebhookMaxPending.
+func WithMaxPending(max int) WebhookOption {
+	return func(wr *WebhookReporter) {
+		if max <= 0 {
+			max = DefaultWebhookMaxPending
+		}
+		wr.maxPending = max
+	}
+}
+
+// WithDedupWindow sets how long after a fingerprint was posted further reports of it are dropped.
+func WithDedupWindow(window time.Duration) WebhookOption {
+	return func(wr *WebhookReporter) {
+		wr.dedupWindow = window
+	}
+}
+
+// WebhookReporter is an ErrorReporter that batches reports and posts them as a WebhookPayload
+// to a generic webhook endpoint. Reports sharing a fingerprint are merged into one report with
+// a Count while pending, and dropped for the dedup window once posted. Batches that fail to
+// post are kept pending for the next flush, up to the max pending limit.
+type WebhookReporter struct {
+	url           string
+	client        *http.Client
+	headers       http.Header
+	batchSize     int
+	flushInterval time.Duration
+	dedupWindow   time.Duration
+	maxPending    int
+
+	mu      sync.Mutex
+	pending []*ErrorReport
+	byPrint map[string]*ErrorReport
+	sent    map[string]time.Time
+	closed  bool
+
+	flushCh   chan struct{}
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	// runCtx is cancelled by Close to abort a background flush in flight.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+}
+
+// NewWebhookReporter creates a WebhookReporter posting to url and starts its background flushing.
+// Close must be called to stop it and post the remaining reports.
+func NewWebhookReporter(url string, opts ...WebhookOption) *WebhookReporter {
+	wr := &WebhookReporter{
+		url:           url,
+		client:        &http.Client{Timeout: DefaultWebhookTimeout},
+		headers:       make(http.Header),
+		batchSize:     DefaultWebhookBatchSize,
+		flushInterval: DefaultWebhookFlushInterval,
+		dedupWindow:   DefaultWebhookDedupWindow,
+		maxPending:    DefaultWebhookMaxPending,
+		byPrint:       make(map[string]*ErrorReport),
+		sent:          make(map[string]time.Time),
+		flushCh:       make(chan struct{}, 1),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(wr)
+	}
+	wr.runCtx, wr.cancelRun = context.WithCancel(context.Background())
+
+	go wr.run()
+
+	return wr
+}
+
+// Report queues report, merging it into a pending report with the same fingerprint.
+// Reports made after Close are dropped.
+func (wr *WebhookReporter) Report(_ context.Context, report ErrorReport) {
+	if report.Fingerprint == "" {
+		report.Fingerprint = Fingerprint(report)
+	}
+	if report.Count == 0 {
+		report.Count = 1
+	}
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	if wr.closed {
+		return
+	}
+
+	if existing, ok := wr.byPrint[report.Fingerprint]; ok {
+		existing.Count += report.Count
+		return
+	}
+
+	if sentAt, ok := wr.sent[report.Fingerprint]; ok && time.Since(sentAt) < wr.dedupWindow {
+		return
+	}
+
+	wr.pending = append(wr.pending, &report)
+	wr.byPrint[report.Fingerprint] = &report
+	wr.dropOldest()
+
+	if len(wr.pending) >= wr.batchSize {
+		select {
+		case wr.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush posts every pending report in a single batch. If posting fails the batch is queued
+// again, merged with the reports made in the meantime.
+func (wr *WebhookReporter) Flush(ctx context.Context) error {
+	wr.mu.Lock()
+	batch := wr.pending
+	wr.pending = nil
+	wr.byPrint = make(map[string]*ErrorReport)
+	wr.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	payload := WebhookPayload{Reports: make([]ErrorReport, 0, len(batch))}
+	for _, report := range batch {
+		payload.Reports = append(payload.Reports, *report)
+	}
+
+	if err := wr.post(ctx, payload); err != nil {
+		wr.requeue(batch)
+		return err
+	}
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	now := time.Now()
+	for fingerprint, sentAt := range wr.sent {
+		if now.Sub(sentAt) >= wr.dedupWindow {
+			delete(wr.sent, fingerprint)
+		}
+	}
+	for _, report := range batch {
+		wr.sent[report.Fingerprint] = now
+	}
+
+	return nil
+}
+
+// requeue puts a batch that failed to post back in front of the pending reports.
+func (wr *WebhookReporter) requeue(batch []*ErrorReport) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	pending := make([]*ErrorReport, 0, len(batch)+len(wr.pending))
+	for _, report := range batch {
+		if existing, ok := wr.byPrint[report.Fingerprint]; ok {
+			report.Count += existing.Count
+		}
+		wr.byPrint[report.Fingerprint] = report
+		pending = append(pending, report)
+	}
+	for _, report := range wr.pending {
+		if wr.byPrint[report.Fingerprint] == report {
+			pending = append(pending, report)
+		}
+	}
+	wr.pending = pending
+	wr.dropOldest()
+}
+
+// dropOldest drops the oldest pending reports beyond the max pending limit. It must be
+// called with wr.mu held.
+func (wr *WebhookReporter) dropOldest() {
+	excess := len(wr.pending) - wr.maxPending
+	if excess <= 0 {
+		return
+	}
+
+	for _, report := range wr.pending[:excess] {
+		delete(wr.byPrint, report.Fingerprint)
+	}
+	wr.pending = append([]*ErrorReport(nil), wr.pending[excess:]...)
+	slog.Warn("dropped pending error reports", "count", excess)
+}
+
+// Close stops background flushing and posts the remaining reports. A background flush in
+// flight is aborted and its batch posted with the rest. Reports made after Close are dropped.
+// Close returns ctx.Err() if ctx is done before the reports are posted.
+func (wr *WebhookReporter) Close(ctx context.Context) error {
+	wr.mu.Lock()
+	wr.closed = true
+	wr.mu.Unlock()
+
+	wr.closeOnce.Do(func() {
+		close(wr.stopCh)
+		wr.cancelRun()
+	})
+
+	select {
+	case <-wr.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return wr.Flush(ctx)
+}
+
+func (wr *WebhookReporter) run() {
+	defer close(wr.doneCh)
+
+	ticker := time.NewTicker(wr.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wr.stopCh:
+			return
+		case <-ticker.C:
+		case <-wr.flushCh:
+		}
+
+		wr.flushInBackground()
+	}
+}
+
+func (wr *WebhookReporter) flushInBackground() {
+	ctx, cancel := context.WithTimeout(wr.runCtx, DefaultWebhookTimeout)
+	defer cancel()
+
+	if err := wr.Flush(ctx); err != nil && wr.runCtx.Err() == nil {
+		slog.Error("unable to post error reports", "error", err)
+	}
+}
+
+func (wr *WebhookReporter) post(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling error reports: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wr.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating error report request: %w", err)
+	}
+	for key := range wr.headers {
+		req.Header.Set(key, wr.headers.Get(key))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := wr.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting error reports: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("error posting error reports: unexpected status %d", res.StatusCode)
+	}
+
+	return nil
+}
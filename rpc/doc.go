@@ -0,0 +1,2 @@
+// package rpc maps siocore errors to and from gRPC statuses
+package rpc
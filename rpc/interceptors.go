@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/slausonio/siocore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor converts AppErrors returned by unary handlers into gRPC statuses.
+// Server errors are passed to siocore.ReportError.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		resp, err := handler(ctx, req)
+		reportError(ctx, err)
+
+		return resp, toStatusError(err)
+	}
+}
+
+// StreamServerInterceptor converts AppErrors returned by stream handlers into gRPC statuses.
+// Server errors are passed to siocore.ReportError.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		err := handler(srv, ss)
+		reportError(ss.Context(), err)
+
+		return toStatusError(err)
+	}
+}
+
+// UnaryClientInterceptor converts statuses returned by unary calls into AppErrors.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor converts statuses returned while opening and using client streams
+// into AppErrors. io.EOF, which marks the end of a stream, is passed through unchanged.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (cs *clientStream) SendMsg(m any) error {
+	return streamError(cs.ClientStream.SendMsg(m))
+}
+
+func (cs *clientStream) RecvMsg(m any) error {
+	return streamError(cs.ClientStream.RecvMsg(m))
+}
+
+func (cs *clientStream) CloseSend() error {
+	return streamError(cs.ClientStream.CloseSend())
+}
+
+func streamError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return FromError(err)
+}
+
+// toStatusError converts err into a status error when its chain holds an AppError and
+// otherwise returns it unchanged.
+func toStatusError(err error) error {
+	var appErr *siocore.AppError
+	if !errors.As(err, &appErr) {
+		return err
+	}
+
+	return ToStatus(err).Err()
+}
+
+// reportError reports err unless it is a status error with a client error code, which
+// siocore.ReportError would otherwise treat as a 500. Canceled and DeadlineExceeded are not
+// reported either as they are caused by the client or its deadline, not a server fault.
+func reportError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return
+	}
+
+	siocore.ReportError(ctx, FromError(err))
+}
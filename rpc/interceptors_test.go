@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/slausonio/siocore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+
+	t.Run("app error", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			return nil, siocore.NewNotFoundError("user not found")
+		})
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "user not found", st.Message())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			return "ok", nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	t.Run("other errors pass through", func(t *testing.T) {
+		plain := errors.New("plain")
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			return nil, plain
+		})
+
+		assert.Same(t, plain, err)
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor()
+
+	err := interceptor(nil, &serverStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(srv any, stream grpc.ServerStream) error {
+		return siocore.NewForbiddenError("no access")
+	})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServerInterceptors_ReportErrors(t *testing.T) {
+	var reports []siocore.ErrorReport
+	unregister := siocore.RegisterErrorReporter(siocore.ErrorReporterFunc(func(ctx context.Context, report siocore.ErrorReport) {
+		reports = append(reports, report)
+	}))
+	defer unregister()
+
+	unary := UnaryServerInterceptor()
+	for _, err := range []error{
+		siocore.NewNotFoundError("user not found"),
+		status.Error(codes.InvalidArgument, "bad input"),
+		siocore.NewInternalServerError("db down"),
+		status.Error(codes.Unavailable, "try later"),
+		status.Error(codes.Canceled, "client went away"),
+		status.Error(codes.DeadlineExceeded, "too slow"),
+		fmt.Errorf("query: %w", context.DeadlineExceeded),
+	} {
+		_, _ = unary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			return nil, err
+		})
+	}
+
+	stream := StreamServerInterceptor()
+	_ = stream(nil, &serverStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(srv any, ss grpc.ServerStream) error {
+		return errors.New("stream broke")
+	})
+
+	if assert.Len(t, reports, 3) {
+		assert.Equal(t, "db down", reports[0].Message)
+		assert.Equal(t, "try later", reports[1].Message)
+		assert.Equal(t, 503, reports[1].Code)
+		assert.Equal(t, "stream broke", reports[2].Message)
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+
+	err := interceptor(context.Background(), "/svc/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return ToStatus(siocore.NewCodedError(siocore.CodeInvalidEmail)).Err()
+		},
+	)
+
+	appErr := &siocore.AppError{}
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 400, appErr.Code)
+	assert.Equal(t, siocore.CodeInvalidEmail, appErr.ErrorCode)
+	assert.Equal(t, siocore.InvalidEmailErr, appErr.Message)
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recvErr error
+}
+
+func (f *fakeClientStream) RecvMsg(any) error {
+	return f.recvErr
+}
+
+func (f *fakeClientStream) SendMsg(any) error {
+	return status.Error(codes.Unavailable, "down")
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	interceptor := StreamClientInterceptor()
+
+	t.Run("stream errors", func(t *testing.T) {
+		fake := &fakeClientStream{recvErr: status.Error(codes.DeadlineExceeded, "slow")}
+		cs, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/svc/Stream",
+			func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return fake, nil
+			},
+		)
+		assert.NoError(t, err)
+
+		code, ok := siocore.StatusCode(cs.RecvMsg(nil))
+		assert.True(t, ok)
+		assert.Equal(t, 504, code)
+
+		code, ok = siocore.StatusCode(cs.SendMsg(nil))
+		assert.True(t, ok)
+		assert.Equal(t, 503, code)
+
+		fake.recvErr = io.EOF
+		assert.Equal(t, io.EOF, cs.RecvMsg(nil))
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		_, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/svc/Stream",
+			func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return nil, status.Error(codes.Unauthenticated, "who")
+			},
+		)
+
+		assert.True(t, siocore.IsUnauthorized(err))
+	})
+}
@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/slausonio/siocore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ErrorInfoDomain is the domain of the ErrorInfo detail attached to converted statuses.
+	ErrorInfoDomain = "siocore"
+	// MetadataHTTPStatus is the ErrorInfo metadata key holding the original AppError code,
+	// so a status converts back to the exact HTTP code it was created from.
+	MetadataHTTPStatus = "http_status"
+)
+
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusRequestTimeout:      codes.DeadlineExceeded,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusGone:                codes.NotFound,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	499:                            codes.Canceled,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusBadGateway:          codes.Unavailable,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+var codeToHTTP = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// HTTPToCode returns the gRPC code for an HTTP status code. Unmapped 4xx codes become
+// FailedPrecondition and everything else Internal.
+func HTTPToCode(httpStatus int) codes.Code {
+	if code, ok := httpToCode[httpStatus]; ok {
+		return code
+	}
+
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
+
+// CodeToHTTP returns the HTTP status code for a gRPC code.
+func CodeToHTTP(code codes.Code) int {
+	if httpStatus, ok := codeToHTTP[code]; ok {
+		return httpStatus
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ToStatus converts err to a gRPC status. The first AppError in err's chain is converted with
+// an ErrorInfo detail carrying its ErrorCode and HTTP code, a BadRequest detail for its field
+// violations, a RetryInfo detail for its RetryAfter and a Struct detail holding its Details
+// as they appear in the problem details response, field violation codes included. Like siocore.WriteError, the message
+// never contains the text of a wrapped cause. Errors already carrying a status keep it and any
+// other error becomes Unknown. ToStatus returns nil for a nil err.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *siocore.AppError
+	if !errors.As(err, &appErr) {
+		return status.Convert(err)
+	}
+
+	pd := appErr.ProblemDetails()
+	message := pd.Detail
+	if message == "" {
+		message = pd.Title
+	}
+
+	st := status.New(HTTPToCode(pd.Status), message)
+
+	details := []protoiface.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   string(appErr.ErrorCode),
+			Domain:   ErrorInfoDomain,
+			Metadata: map[string]string{MetadataHTTPStatus: strconv.Itoa(pd.Status)},
+		},
+	}
+
+	if violations, ok := appErr.Details[siocore.ValidationErrorsMember].([]siocore.FieldViolation); ok {
+		br := &errdetails.BadRequest{}
+		for _, v := range violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+		details = append(details, br)
+	}
+
+	if appErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(appErr.RetryAfter)})
+	}
+
+	if extensions := detailsStruct(appErr.Details); extensions != nil {
+		details = append(details, extensions)
+	}
+
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// FromStatus converts a gRPC status back into an AppError, restoring the ErrorCode, exact HTTP
+// code, Details, field violations and retry delay from the details written by ToStatus. The
+// ErrorCode, HTTP code and Details are only taken from statuses carrying an ErrorInfo of
+// ErrorInfoDomain, and the HTTP code only if it is a 4xx or 5xx code; anything else is mapped
+// through CodeToHTTP. FromStatus returns nil for an OK status.
+func FromStatus(st *status.Status) *siocore.AppError {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	appErr := siocore.NewAppError(st.Message(), CodeToHTTP(st.Code()))
+
+	var extensions *structpb.Struct
+	trusted := false
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != ErrorInfoDomain {
+				continue
+			}
+
+			trusted = true
+			appErr.ErrorCode = siocore.ErrorCode(d.GetReason())
+			code, err := strconv.Atoi(d.GetMetadata()[MetadataHTTPStatus])
+			if err == nil && code >= 400 && code <= 599 {
+				appErr.Code = code
+			}
+		case *errdetails.BadRequest:
+			violations := make([]siocore.FieldViolation, 0, len(d.GetFieldViolations()))
+			for _, v := range d.GetFieldViolations() {
+				violations = append(violations, siocore.FieldViolation{
+					Field:   v.GetField(),
+					Message: v.GetDescription(),
+				})
+			}
+			appErr.Details = map[string]any{siocore.ValidationErrorsMember: violations}
+		case *errdetails.RetryInfo:
+			appErr.RetryAfter = d.GetRetryDelay().AsDuration()
+		case *structpb.Struct:
+			extensions = d
+		}
+	}
+
+	if trusted && extensions != nil {
+		appErr.Details = restoreDetails(extensions)
+	}
+
+	return appErr
+}
+
+// detailsStruct converts Details to a Struct through their JSON form, returning nil if there
+// are none or they cannot be represented.
+func detailsStruct(details map[string]any) *structpb.Struct {
+	if len(details) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+
+	extensions, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil
+	}
+
+	return extensions
+}
+
+// restoreDetails converts a Struct written by detailsStruct back to Details, decoding the
+// field violations into their original type.
+func restoreDetails(extensions *structpb.Struct) map[string]any {
+	details := extensions.AsMap()
+
+	if raw, ok := details[siocore.ValidationErrorsMember]; ok {
+		var violations []siocore.FieldViolation
+		if b, err := json.Marshal(raw); err == nil && json.Unmarshal(b, &violations) == nil {
+			details[siocore.ValidationErrorsMember] = violations
+		}
+	}
+
+	return details
+}
+
+// FromError converts an error returned by a gRPC call into an AppError. Errors that do not
+// carry a status are returned unchanged, as is nil.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return FromStatus(st)
+}
@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/slausonio/siocore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPToCode(t *testing.T) {
+	tt := []struct {
+		httpStatus int
+		expected   codes.Code
+	}{
+		{httpStatus: 400, expected: codes.InvalidArgument},
+		{httpStatus: 401, expected: codes.Unauthenticated},
+		{httpStatus: 403, expected: codes.PermissionDenied},
+		{httpStatus: 404, expected: codes.NotFound},
+		{httpStatus: 409, expected: codes.Aborted},
+		{httpStatus: 412, expected: codes.FailedPrecondition},
+		{httpStatus: 418, expected: codes.FailedPrecondition},
+		{httpStatus: 429, expected: codes.ResourceExhausted},
+		{httpStatus: 500, expected: codes.Internal},
+		{httpStatus: 503, expected: codes.Unavailable},
+		{httpStatus: 504, expected: codes.DeadlineExceeded},
+		{httpStatus: 0, expected: codes.Internal},
+	}
+
+	for _, tc := range tt {
+		t.Run(http.StatusText(tc.httpStatus), func(t *testing.T) {
+			assert.Equal(t, tc.expected, HTTPToCode(tc.httpStatus))
+		})
+	}
+}
+
+func TestCodeToHTTP(t *testing.T) {
+	assert.Equal(t, 404, CodeToHTTP(codes.NotFound))
+	assert.Equal(t, 409, CodeToHTTP(codes.AlreadyExists))
+	assert.Equal(t, 401, CodeToHTTP(codes.Unauthenticated))
+	assert.Equal(t, 503, CodeToHTTP(codes.Unavailable))
+	assert.Equal(t, 500, CodeToHTTP(codes.Code(99)))
+}
+
+func TestToStatus(t *testing.T) {
+	appErr := siocore.NewCodedError(siocore.CodeTokenInvalid)
+
+	st := ToStatus(fmt.Errorf("wrapped: %w", appErr))
+
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.Equal(t, siocore.TokenInvalid, st.Message())
+
+	details := st.Details()
+	assert.Len(t, details, 1)
+	info := details[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, string(siocore.CodeTokenInvalid), info.GetReason())
+	assert.Equal(t, ErrorInfoDomain, info.GetDomain())
+	assert.Equal(t, "401", info.GetMetadata()[MetadataHTTPStatus])
+}
+
+func TestToStatus_DoesNotLeakCause(t *testing.T) {
+	st := ToStatus(siocore.Wrap(errors.New("pq: connection refused"), 500, ""))
+
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, http.StatusText(500), st.Message())
+}
+
+func TestToStatus_NonAppError(t *testing.T) {
+	assert.Nil(t, ToStatus(nil))
+
+	existing := status.Error(codes.NotFound, "gone")
+	assert.Equal(t, codes.NotFound, ToStatus(existing).Code())
+
+	assert.Equal(t, codes.Unknown, ToStatus(errors.New("plain")).Code())
+}
+
+func TestStatus_RoundTrip(t *testing.T) {
+	t.Run("precondition keeps exact code", func(t *testing.T) {
+		original := siocore.NewPreconditionFailedError("etag mismatch")
+
+		got := FromStatus(ToStatus(original))
+
+		assert.Equal(t, 412, got.Code)
+		assert.Equal(t, "etag mismatch", got.Message)
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		original := siocore.NewTooManyRequestsError("slow down", 3*time.Second)
+
+		got := FromStatus(ToStatus(original))
+
+		assert.True(t, siocore.IsTooManyRequests(got))
+		assert.Equal(t, 3*time.Second, got.RetryAfter)
+	})
+
+	t.Run("validation violations", func(t *testing.T) {
+		original := siocore.NewValidationError().
+			AddCode("email", siocore.CodeInvalidEmail, "bad").
+			AddCode("phone", siocore.CodeInvalidPhone, "123")
+
+		st := ToStatus(original)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+
+		got := FromStatus(st)
+		assert.Equal(t, 400, got.Code)
+		assert.Equal(t, []siocore.FieldViolation{
+			{Field: "email", Code: siocore.CodeInvalidEmail, Message: siocore.InvalidEmailErr, RejectedValue: "bad"},
+			{Field: "phone", Code: siocore.CodeInvalidPhone, Message: siocore.InvalidPhoneErr, RejectedValue: "123"},
+		}, got.Details[siocore.ValidationErrorsMember])
+	})
+
+	t.Run("details", func(t *testing.T) {
+		original := siocore.NewConflictError("duplicate")
+		original.Details = map[string]any{"resource": "user", "attempts": 2}
+
+		got := FromStatus(ToStatus(original))
+
+		assert.Equal(t, map[string]any{"resource": "user", "attempts": float64(2)}, got.Details)
+	})
+}
+
+func TestFromStatus_UntrustedErrorInfo(t *testing.T) {
+	withInfo := func(domain, httpStatus string) *status.Status {
+		st, err := status.New(codes.NotFound, "missing").WithDetails(&errdetails.ErrorInfo{
+			Reason:   string(siocore.CodeTokenInvalid),
+			Domain:   domain,
+			Metadata: map[string]string{MetadataHTTPStatus: httpStatus},
+		})
+		assert.NoError(t, err)
+
+		return st
+	}
+
+	got := FromStatus(withInfo("other.example.com", "401"))
+	assert.Equal(t, 404, got.Code, "foreign domains are mapped through CodeToHTTP")
+	assert.Equal(t, siocore.ErrorCode(""), got.ErrorCode)
+
+	for _, httpStatus := range []string{"200", "302", "600", "-1"} {
+		got := FromStatus(withInfo(ErrorInfoDomain, httpStatus))
+		assert.Equal(t, 404, got.Code, httpStatus)
+		assert.Equal(t, siocore.CodeTokenInvalid, got.ErrorCode)
+	}
+}
+
+func TestFromStatus_Foreign(t *testing.T) {
+	got := FromStatus(status.New(codes.AlreadyExists, "duplicate"))
+
+	assert.Equal(t, 409, got.Code)
+	assert.Equal(t, "duplicate", got.Error())
+	assert.Equal(t, siocore.ErrorCode(""), got.ErrorCode)
+
+	assert.Nil(t, FromStatus(status.New(codes.OK, "")))
+	assert.Nil(t, FromStatus(nil))
+}
+
+func TestFromError(t *testing.T) {
+	assert.NoError(t, FromError(nil))
+
+	plain := errors.New("plain")
+	assert.Same(t, plain, FromError(plain))
+
+	err := FromError(status.Error(codes.NotFound, "missing"))
+	assert.True(t, siocore.IsNotFound(err))
+}
@@ -0,0 +1,2 @@
+// package validate provides struct tag driven validation producing siocore field errors
+package validate
@@ -0,0 +1,170 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/slausonio/siocore"
+)
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02", "01/02/2006"}
+
+func builtinRules() map[string]Rule {
+	return map[string]Rule{
+		"email":       emailRule,
+		"password":    passwordRule,
+		"name":        nameRule,
+		"description": descriptionRule,
+		"phone":       phoneRule,
+		"numeric":     numericRule,
+		"dob":         dobRule,
+		"minAge":      minAgeRule,
+		"after":       afterRule,
+	}
+}
+
+func emailRule(fc FieldContext) error {
+	s, err := stringValue(fc)
+	if err != nil {
+		return err
+	}
+
+	return siocore.ValidateEmail(s)
+}
+
+func passwordRule(fc FieldContext) error {
+	s, err := stringValue(fc)
+	if err != nil {
+		return err
+	}
+
+	return fc.PasswordPolicy.Validate(s)
+}
+
+func nameRule(fc FieldContext) error {
+	s, err := stringValue(fc)
+	if err != nil {
+		return err
+	}
+
+	return siocore.ValidateName(s)
+}
+
+func descriptionRule(fc FieldContext) error {
+	s, err := stringValue(fc)
+	if err != nil {
+		return err
+	}
+
+	return siocore.ValidateDescription(s)
+}
+
+func phoneRule(fc FieldContext) error {
+	s, err := stringValue(fc)
+	if err != nil {
+		return err
+	}
+
+	return siocore.ValidatePhone(s)
+}
+
+func numericRule(fc FieldContext) error {
+	switch fc.Value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return nil
+	case reflect.String:
+		return siocore.ValidateNumericID(fc.Value.String())
+	default:
+		return fmt.Errorf("%w: numeric requires an integer or string field", ErrInvalidRule)
+	}
+}
+
+func dobRule(fc FieldContext) error {
+	dob, err := timeValue(fc.Value)
+	if err != nil {
+		return dateError(err, siocore.CodeInvalidDob)
+	}
+
+	return siocore.ValidateDOB(dob, siocore.FixedClock(fc.Now))
+}
+
+func minAgeRule(fc FieldContext) error {
+	minAge, err := strconv.Atoi(fc.Param)
+	if err != nil {
+		return fmt.Errorf("%w: minAge requires an integer parameter", ErrInvalidRule)
+	}
+
+	dob, err := timeValue(fc.Value)
+	if err != nil {
+		return dateError(err, siocore.CodeInvalidDob)
+	}
+
+	return siocore.ValidateMinimumAge(dob, minAge, siocore.FixedClock(fc.Now))
+}
+
+func afterRule(fc FieldContext) error {
+	other := fc.Parent.FieldByName(fc.Param)
+	if !other.IsValid() {
+		return fmt.Errorf("%w: after references unknown field %q", ErrInvalidRule, fc.Param)
+	}
+	for other.Kind() == reflect.Pointer {
+		if other.IsNil() {
+			return nil
+		}
+		other = other.Elem()
+	}
+	if isEmpty(other) {
+		return nil
+	}
+
+	start, err := timeValue(other)
+	if err != nil {
+		return dateError(err, siocore.CodeInvalidEndDate)
+	}
+
+	end, err := timeValue(fc.Value)
+	if err != nil {
+		return dateError(err, siocore.CodeInvalidEndDate)
+	}
+
+	return siocore.ValidateDateRange(start, end)
+}
+
+// dateError passes rule misconfigurations through and reports unparsable dates as code.
+func dateError(err error, code siocore.ErrorCode) error {
+	if errors.Is(err, ErrInvalidRule) {
+		return err
+	}
+
+	return siocore.NewCodedError(code)
+}
+
+func stringValue(fc FieldContext) (string, error) {
+	if fc.Value.Kind() != reflect.String {
+		return "", fmt.Errorf("%w: rule requires a string field", ErrInvalidRule)
+	}
+
+	return fc.Value.String(), nil
+}
+
+// timeValue reads a time.Time field or parses a string field in RFC 3339, 2006-01-02 or 01/02/2006 layout.
+func timeValue(v reflect.Value) (time.Time, error) {
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time), nil
+	case v.Kind() == reflect.String:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v.String()); err == nil {
+				return t, nil
+			}
+		}
+
+		return time.Time{}, fmt.Errorf("unable to parse %q as a date", v.String())
+	default:
+		return time.Time{}, fmt.Errorf("%w: rule requires a time.Time or string field", ErrInvalidRule)
+	}
+}
@@ -0,0 +1,342 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slausonio/siocore"
+)
+
+const TagName = "validate"
+
+var (
+	ErrNotStruct   = errors.New("validate: value is not a struct or pointer to a struct")
+	ErrInvalidRule = errors.New("validate: invalid rule")
+)
+
+// FieldContext is passed to a Rule with the field being validated.
+type FieldContext struct {
+	// Name is the Go name of the field and Path its full path as reported in violations.
+	Name string
+	Path string
+	// Value is the field value, dereferenced if the field is a pointer.
+	Value reflect.Value
+	// Parent is the struct holding the field, used by rules comparing fields.
+	Parent reflect.Value
+	// Param is the text after "=" in the tag, e.g. "13" for minAge=13.
+	Param string
+	// Now is the validator's clock reading, fixed for the whole Struct call.
+	Now time.Time
+	// PasswordPolicy is the policy enforced by the password rule.
+	PasswordPolicy siocore.PasswordPolicy
+}
+
+// Rule validates a single field. A returned error is recorded as a violation of the field;
+// siocore.AppErrors keep their ErrorCode and message. Errors wrapping ErrInvalidRule
+// signal a misconfigured tag and abort validation instead.
+type Rule func(fc FieldContext) error
+
+// Option configures a Validator.
+type Option func(*Validator)
+
+// WithClock sets the clock used by date rules such as dob and minAge.
+func WithClock(clock siocore.Clock) Option {
+	return func(v *Validator) {
+		if clock != nil {
+			v.clock = clock
+		}
+	}
+}
+
+// WithNow sets the clock used by date rules such as dob and minAge from a function.
+func WithNow(now func() time.Time) Option {
+	if now == nil {
+		return WithClock(nil)
+	}
+
+	return WithClock(siocore.ClockFunc(now))
+}
+
+// WithPasswordPolicy sets the policy enforced by the password rule.
+// Defaults to siocore.DefaultPasswordPolicy.
+func WithPasswordPolicy(policy siocore.PasswordPolicy) Option {
+	return func(v *Validator) {
+		v.passwordPolicy = policy
+	}
+}
+
+// WithRejectedValues reports the value of a field with its violations, so clients can see
+// what was rejected. Values are not reported by default as they may hold tokens or personal
+// data, and fields with the password rule are never reported.
+func WithRejectedValues() Option {
+	return func(v *Validator) {
+		v.rejectedValues = true
+	}
+}
+
+// Validator validates structs according to their `validate` tags, e.g.
+//
+//	type SignUp struct {
+//		Email     string    `json:"email" validate:"required,email"`
+//		Password  string    `json:"password" validate:"required,password"`
+//		Dob       time.Time `json:"dob" validate:"required,dob,minAge=13"`
+//		StartDate time.Time `json:"startDate"`
+//		EndDate   time.Time `json:"endDate" validate:"after=StartDate"`
+//	}
+//
+// Rules run in tag order. Fields without the required rule that hold their zero value
+// skip their remaining rules. Nested structs, pointers to structs and slices of structs
+// are validated recursively, and violations are reported by their json path.
+type Validator struct {
+	mu             sync.RWMutex
+	rules          map[string]Rule
+	clock          siocore.Clock
+	passwordPolicy siocore.PasswordPolicy
+	rejectedValues bool
+}
+
+// New creates a Validator with the built-in rules registered.
+func New(opts ...Option) *Validator {
+	v := &Validator{
+		rules:          make(map[string]Rule),
+		clock:          siocore.SystemClock,
+		passwordPolicy: siocore.DefaultPasswordPolicy,
+	}
+	for name, rule := range builtinRules() {
+		v.rules[name] = rule
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// RegisterRule adds a custom rule usable in tags by name, replacing any rule of that name.
+func (v *Validator) RegisterRule(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rules[name] = rule
+}
+
+// Struct validates s, returning a *siocore.ValidationError holding every violation or nil if
+// s is valid. Any other error means s or one of its tags could not be validated.
+func (v *Validator) Struct(s any) error {
+	rv := reflect.ValueOf(s)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ErrNotStruct
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	ve := siocore.NewValidationError()
+	if err := v.validateStruct(rv, "", ve, v.clock.Now()); err != nil {
+		return err
+	}
+
+	return ve.Err()
+}
+
+func (v *Validator) validateStruct(rv reflect.Value, path string, ve *siocore.ValidationError, now time.Time) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		fieldPath := joinPath(path, fieldName(sf))
+		if sf.Anonymous && indirectType(sf.Type).Kind() == reflect.Struct {
+			fieldPath = path
+		}
+
+		if tag != "" {
+			fc := FieldContext{
+				Name:           sf.Name,
+				Path:           fieldPath,
+				Parent:         rv,
+				Now:            now,
+				PasswordPolicy: v.passwordPolicy,
+			}
+			if err := v.validateField(fv, tag, fc, ve); err != nil {
+				return err
+			}
+		}
+
+		if err := v.dive(fv, fieldPath, ve, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) validateField(fv reflect.Value, tag string, fc FieldContext, ve *siocore.ValidationError) error {
+	type boundRule struct {
+		rule  Rule
+		param string
+	}
+
+	// rules are resolved before the field is inspected so a misspelled rule is reported
+	// even while an optional field is empty
+	var rules []boundRule
+	sensitive := false
+	required := false
+	for _, raw := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(raw), "=")
+		switch name {
+		case "":
+			continue
+		case "required":
+			required = true
+			continue
+		case "password":
+			sensitive = true
+		}
+
+		rule, ok := v.rule(name)
+		if !ok {
+			return fmt.Errorf("%w: unknown rule %q on field %s", ErrInvalidRule, name, fc.Path)
+		}
+		rules = append(rules, boundRule{rule: rule, param: param})
+	}
+
+	value := fv
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if isEmpty(value) {
+		if required {
+			ve.AddCode(fc.Path, siocore.CodeRequired, nil)
+		}
+
+		return nil
+	}
+
+	var rejected any
+	if v.rejectedValues && !sensitive && value.CanInterface() {
+		rejected = value.Interface()
+	}
+
+	for _, br := range rules {
+		fc.Value = value
+		fc.Param = br.param
+		err := br.rule(fc)
+		if errors.Is(err, ErrInvalidRule) {
+			return fmt.Errorf("field %s: %w", fc.Path, err)
+		}
+
+		ve.AddError(fc.Path, err, rejected)
+	}
+
+	return nil
+}
+
+// dive validates nested structs held by fv directly, through pointers or in slices and arrays.
+func (v *Validator) dive(fv reflect.Value, path string, ve *siocore.ValidationError, now time.Time) error {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() == timeType {
+			return nil
+		}
+
+		return v.validateStruct(fv, path, ve, now)
+	case reflect.Slice, reflect.Array:
+		if indirectType(fv.Type().Elem()).Kind() != reflect.Struct {
+			return nil
+		}
+
+		for i := 0; i < fv.Len(); i++ {
+			if err := v.dive(fv.Index(i), fmt.Sprintf("%s[%d]", path, i), ve, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) rule(name string) (Rule, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	rule, ok := v.rules[name]
+	return rule, ok
+}
+
+var defaultValidator = New()
+
+// RegisterRule adds a custom rule to the default validator.
+func RegisterRule(name string, rule Rule) {
+	defaultValidator.RegisterRule(name, rule)
+}
+
+// Struct validates s with the default validator.
+func Struct(s any) error {
+	return defaultValidator.Struct(s)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// fieldName returns the json name of the field, falling back to its Go name.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+
+	return name
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
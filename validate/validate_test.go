@@ -0,0 +1,251 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slausonio/siocore"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+type address struct {
+	Phone string `json:"phone" validate:"phone"`
+}
+
+type signUp struct {
+	Email       string    `json:"email" validate:"required,email"`
+	Password    string    `json:"password" validate:"required,password"`
+	Name        string    `json:"name" validate:"required,name"`
+	Description string    `json:"description" validate:"description"`
+	Dob         time.Time `json:"dob" validate:"required,dob,minAge=13"`
+	ID          string    `json:"id" validate:"numeric"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate" validate:"after=StartDate"`
+	Address     *address  `json:"address"`
+	Contacts    []address `json:"contacts"`
+	Ignored     string    `validate:"-"`
+}
+
+func validSignUp() signUp {
+	return signUp{
+		Email:       "user@example.com",
+		Password:    "Passw0rd!",
+		Name:        "Mary-Jane O'Neil",
+		Description: "hello\nworld",
+		Dob:         time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		ID:          "123",
+		StartDate:   testNow,
+		EndDate:     testNow.Add(time.Hour),
+		Address:     &address{Phone: "(555) 123-4567"},
+		Contacts:    []address{{Phone: "+1 555 123 4567"}},
+	}
+}
+
+func violations(t *testing.T, err error) map[string]siocore.FieldViolation {
+	t.Helper()
+
+	var ve *siocore.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	result := make(map[string]siocore.FieldViolation, len(ve.Violations))
+	for _, v := range ve.Violations {
+		result[v.Field] = v
+	}
+
+	return result
+}
+
+func TestStruct_Valid(t *testing.T) {
+	v := New(WithNow(func() time.Time { return testNow }))
+
+	s := validSignUp()
+	assert.NoError(t, v.Struct(s))
+	assert.NoError(t, v.Struct(&s))
+}
+
+func TestStruct_Violations(t *testing.T) {
+	v := New(WithNow(func() time.Time { return testNow }))
+
+	s := signUp{
+		Email:       "not-an-email",
+		Password:    "password",
+		Description: strings.Repeat("a", siocore.MaxDescriptionLength+1),
+		Dob:         testNow.AddDate(-10, 0, 0),
+		ID:          "abc",
+		StartDate:   testNow,
+		EndDate:     testNow.Add(-time.Hour),
+		Address:     &address{Phone: "555-1234"},
+		Contacts:    []address{{Phone: "5551234567"}, {Phone: "12"}},
+		Ignored:     "anything",
+	}
+
+	got := violations(t, v.Struct(s))
+
+	expected := map[string]siocore.ErrorCode{
+		"email":             siocore.CodeInvalidEmail,
+		"password":          siocore.CodeInvalidPassword,
+		"name":              siocore.CodeRequired,
+		"description":       siocore.CodeInvalidDescription,
+		"dob":               siocore.CodeInvalidAge,
+		"id":                siocore.CodeInvalidID,
+		"endDate":           siocore.CodeInvalidEndDate,
+		"address.phone":     siocore.CodeInvalidPhone,
+		"contacts[1].phone": siocore.CodeInvalidPhone,
+	}
+	assert.Len(t, got, len(expected))
+	for field, code := range expected {
+		assert.Equal(t, code, got[field].Code, field)
+	}
+
+	assert.Equal(t, siocore.InvalidEmailErr, got["email"].Message)
+	assert.Nil(t, got["email"].RejectedValue, "values are not echoed back by default")
+	assert.Equal(t, siocore.InvalidPasswordErr, got["password"].Message)
+	assert.Equal(t, siocore.InvalidAgeErr, got["dob"].Message)
+	assert.Equal(t, siocore.InvalidEndDateErr, got["endDate"].Message)
+	assert.Equal(t, siocore.InvalidPhoneErr, got["address.phone"].Message)
+}
+
+func TestStruct_SharedValidators(t *testing.T) {
+	type contact struct {
+		Phone    string `validate:"phone"`
+		Password string `validate:"password"`
+	}
+
+	got := violations(t, New().Struct(contact{Phone: "555+123+4567", Password: "Password1!"}))
+	assert.Len(t, got, 1)
+	assert.Equal(t, siocore.CodeInvalidPhone, got["Phone"].Code, "the rule agrees with siocore.ValidatePhone")
+
+	policy := siocore.PasswordPolicy{MinLength: 12, RequireLower: true}
+	v := New(WithPasswordPolicy(policy))
+	got = violations(t, v.Struct(contact{Phone: "5551234567", Password: "Password1!"}))
+	assert.Equal(t, siocore.CodeInvalidPassword, got["Password"].Code)
+	assert.NoError(t, v.Struct(contact{Phone: "5551234567", Password: "a long passphrase"}))
+}
+
+func TestStruct_RejectedValues(t *testing.T) {
+	v := New(WithNow(func() time.Time { return testNow }), WithRejectedValues())
+
+	s := validSignUp()
+	s.Email = "not-an-email"
+	s.Password = "password"
+
+	got := violations(t, v.Struct(s))
+	assert.Equal(t, "not-an-email", got["email"].RejectedValue)
+	assert.Nil(t, got["password"].RejectedValue, "passwords must not be echoed back")
+}
+
+func TestStruct_Dob(t *testing.T) {
+	type person struct {
+		Dob string `validate:"dob,minAge=21"`
+	}
+
+	v := New(WithClock(siocore.FixedClock(testNow)))
+
+	tt := []struct {
+		name     string
+		dob      string
+		expected siocore.ErrorCode
+		message  string
+	}{
+		{name: "valid", dob: "1990-05-01"},
+		{name: "future", dob: "2030-01-01", expected: siocore.CodeInvalidAge, message: "customer must be at least 21 years of age"},
+		{name: "unparsable", dob: "yesterday", expected: siocore.CodeInvalidDob, message: siocore.InvalidDobErr},
+		{name: "turns 21 today", dob: "2003-06-15"},
+		{name: "turns 21 tomorrow", dob: "2003-06-16", expected: siocore.CodeInvalidAge},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v.Struct(person{Dob: tc.dob})
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			got := violations(t, err)
+			assert.Equal(t, tc.expected, got["Dob"].Code)
+			if tc.message != "" {
+				assert.Equal(t, tc.message, got["Dob"].Message)
+			}
+		})
+	}
+}
+
+func TestStruct_CustomRule(t *testing.T) {
+	type order struct {
+		Currency string `json:"currency" validate:"required,currency"`
+	}
+
+	v := New(WithRejectedValues())
+	v.RegisterRule("currency", func(fc FieldContext) error {
+		if fc.Value.String() != "USD" {
+			return siocore.NewBadRequestError("unsupported currency")
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, v.Struct(order{Currency: "USD"}))
+
+	got := violations(t, v.Struct(order{Currency: "EUR"}))
+	assert.Equal(t, "unsupported currency", got["currency"].Message)
+	assert.Equal(t, "EUR", got["currency"].RejectedValue)
+}
+
+func TestStruct_Misconfigured(t *testing.T) {
+	v := New()
+
+	t.Run("unknown rule", func(t *testing.T) {
+		err := v.Struct(struct {
+			A string `validate:"bogus"`
+		}{A: "x"})
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	})
+
+	t.Run("unknown rule on empty optional field", func(t *testing.T) {
+		err := v.Struct(struct {
+			A string `validate:"emial"`
+		}{})
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	})
+
+	t.Run("rule on wrong type", func(t *testing.T) {
+		err := v.Struct(struct {
+			A int `validate:"email"`
+		}{A: 1})
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	})
+
+	t.Run("bad param", func(t *testing.T) {
+		err := v.Struct(struct {
+			A time.Time `validate:"minAge=old"`
+		}{A: testNow})
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	})
+
+	t.Run("unknown after field", func(t *testing.T) {
+		err := v.Struct(struct {
+			A time.Time `validate:"after=Missing"`
+		}{A: testNow})
+		assert.ErrorIs(t, err, ErrInvalidRule)
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		assert.ErrorIs(t, v.Struct("nope"), ErrNotStruct)
+		assert.ErrorIs(t, v.Struct((*signUp)(nil)), ErrNotStruct)
+	})
+}
+
+func TestStruct_WriteErrorStatus(t *testing.T) {
+	err := Struct(struct {
+		Email string `json:"email" validate:"required"`
+	}{})
+
+	assert.True(t, siocore.IsBadRequest(err))
+}
@@ -0,0 +1,169 @@
+package siocore
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ValidationErrorsMember is the AppError.Details key, and problem details member, holding the
+// field violations of a ValidationError.
+const ValidationErrorsMember = "errors"
+
+// FieldViolation describes why the value of a single field was rejected.
+// Field is the path of the field, e.g. "address.zip" or "items[2].name".
+// RejectedValue is sent to clients, so it must only be set for values safe to expose.
+type FieldViolation struct {
+	Field         string    `json:"field"`
+	Code          ErrorCode `json:"code"`
+	Message       string    `json:"message"`
+	RejectedValue any       `json:"rejectedValue,omitempty"`
+}
+
+// ValidationError aggregates field violations so every problem with a request can be reported
+// at once. It is built up with Add and Merge and, through Unwrap, behaves as an AppError with
+// the violations in its Details.
+type ValidationError struct {
+	// Status is the code of the resulting AppError, 400 unless set to e.g. 422.
+	Status     int
+	Violations []FieldViolation
+}
+
+// NewValidationError creates an empty 400 ValidationError.
+func NewValidationError() *ValidationError {
+	return &ValidationError{Status: http.StatusBadRequest}
+}
+
+// Add records a violation for field.
+func (ve *ValidationError) Add(field string, code ErrorCode, message string, rejected any) *ValidationError {
+	ve.Violations = append(ve.Violations, FieldViolation{
+		Field:         field,
+		Code:          code,
+		Message:       message,
+		RejectedValue: rejected,
+	})
+
+	return ve
+}
+
+// AddCode records a violation for field using the DefaultCatalog message for code.
+func (ve *ValidationError) AddCode(field string, code ErrorCode, rejected any) *ValidationError {
+	message := string(code)
+	if entry, ok := DefaultCatalog.Lookup(code); ok {
+		message = entry.Message
+	}
+
+	return ve.Add(field, code, message, rejected)
+}
+
+// AddError records err as a violation for field. A ValidationError is merged below field,
+// an AppError keeps its ErrorCode and message and any other error is recorded by its text.
+// A nil err is ignored.
+func (ve *ValidationError) AddError(field string, err error, rejected any) *ValidationError {
+	if err == nil {
+		return ve
+	}
+
+	var nested *ValidationError
+	if errors.As(err, &nested) {
+		return ve.MergePrefixed(field, nested)
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return ve.Add(field, appErr.ErrorCode, appErr.Message, rejected)
+	}
+
+	return ve.Add(field, CodeUnknown, err.Error(), rejected)
+}
+
+// Merge appends the violations of others. The highest status wins.
+func (ve *ValidationError) Merge(others ...*ValidationError) *ValidationError {
+	for _, other := range others {
+		ve.MergePrefixed("", other)
+	}
+
+	return ve
+}
+
+// MergePrefixed appends the violations of other with their fields nested below prefix.
+func (ve *ValidationError) MergePrefixed(prefix string, other *ValidationError) *ValidationError {
+	if other == nil {
+		return ve
+	}
+
+	for _, v := range other.Violations {
+		v.Field = joinFieldPath(prefix, v.Field)
+		ve.Violations = append(ve.Violations, v)
+	}
+
+	if other.Status > ve.Status {
+		ve.Status = other.Status
+	}
+
+	return ve
+}
+
+// HasViolations reports whether any violation has been recorded.
+func (ve *ValidationError) HasViolations() bool {
+	return len(ve.Violations) > 0
+}
+
+// Err returns the ValidationError as an error, or nil if there are no violations.
+func (ve *ValidationError) Err() error {
+	if !ve.HasViolations() {
+		return nil
+	}
+
+	return ve
+}
+
+func (ve *ValidationError) Error() string {
+	parts := make([]string, 0, len(ve.Violations))
+	for _, v := range ve.Violations {
+		if v.Field == "" {
+			parts = append(parts, v.Message)
+			continue
+		}
+
+		parts = append(parts, v.Field+": "+v.Message)
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// AppError renders the ValidationError as an AppError with the violations under
+// Details[ValidationErrorsMember].
+func (ve *ValidationError) AppError() *AppError {
+	status := ve.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+
+	violations := make([]FieldViolation, len(ve.Violations))
+	copy(violations, ve.Violations)
+
+	appErr := newAppError("validation failed", status, nil)
+	appErr.Details = map[string]any{ValidationErrorsMember: violations}
+
+	return appErr
+}
+
+// Unwrap exposes the ValidationError as an AppError so it works with WriteError and the
+// status predicates.
+func (ve *ValidationError) Unwrap() error {
+	return ve.AppError()
+}
+
+func joinFieldPath(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	case strings.HasPrefix(field, "["):
+		return prefix + field
+	default:
+		return prefix + "." + field
+	}
+}
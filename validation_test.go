@@ -0,0 +1,105 @@
+package siocore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError_Build(t *testing.T) {
+	ve := NewValidationError().
+		AddCode("email", CodeInvalidEmail, "nope").
+		Add("name", CodeInvalidName, "name is too long", "x")
+
+	assert.True(t, ve.HasViolations())
+	assert.Equal(t, "validation failed: email: invalid email; name: name is too long", ve.Error())
+	assert.Equal(t, []FieldViolation{
+		{Field: "email", Code: CodeInvalidEmail, Message: InvalidEmailErr, RejectedValue: "nope"},
+		{Field: "name", Code: CodeInvalidName, Message: "name is too long", RejectedValue: "x"},
+	}, ve.Violations)
+}
+
+func TestValidationError_Err(t *testing.T) {
+	assert.NoError(t, NewValidationError().Err())
+
+	err := NewValidationError().AddCode("phone", CodeInvalidPhone, "123").Err()
+	assert.Error(t, err)
+}
+
+func TestValidationError_Merge(t *testing.T) {
+	address := NewValidationError().Add("zip", "VALIDATION.INVALID_ZIP", "invalid zip", "abc")
+	address.Status = 422
+	items := NewValidationError().Add("[0].name", CodeInvalidName, InvalidNameErr, "")
+
+	ve := NewValidationError().
+		AddCode("email", CodeInvalidEmail, "").
+		MergePrefixed("address", address).
+		MergePrefixed("items", items).
+		Merge(nil, NewValidationError().Add("", CodeUnknown, "general", nil))
+
+	fields := make([]string, 0, len(ve.Violations))
+	for _, v := range ve.Violations {
+		fields = append(fields, v.Field)
+	}
+
+	assert.Equal(t, []string{"email", "address.zip", "items[0].name", ""}, fields)
+	assert.Equal(t, 422, ve.Status)
+}
+
+func TestValidationError_AddError(t *testing.T) {
+	nested := NewValidationError().AddCode("city", CodeInvalidName, nil)
+
+	ve := NewValidationError().
+		AddError("email", NewCodedError(CodeInvalidEmail), "bad").
+		AddError("address", nested, nil).
+		AddError("other", errors.New("boom"), 1).
+		AddError("ignored", nil, nil)
+
+	assert.Equal(t, []FieldViolation{
+		{Field: "email", Code: CodeInvalidEmail, Message: InvalidEmailErr, RejectedValue: "bad"},
+		{Field: "address.city", Code: CodeInvalidName, Message: InvalidNameErr},
+		{Field: "other", Code: CodeUnknown, Message: "boom", RejectedValue: 1},
+	}, ve.Violations)
+}
+
+func TestValidationError_AsAppError(t *testing.T) {
+	ve := NewValidationError().AddCode("email", CodeInvalidEmail, "bad")
+	err := fmt.Errorf("create user: %w", ve)
+
+	assert.True(t, IsBadRequest(err))
+
+	ve.Status = 422
+	assert.True(t, IsUnprocessableEntity(err))
+
+	var target *ValidationError
+	assert.ErrorAs(t, err, &target)
+	assert.Same(t, ve, target)
+}
+
+func TestValidationError_WriteError(t *testing.T) {
+	ve := NewValidationError().
+		AddCode("email", CodeInvalidEmail, "bad").
+		AddCode("phone", CodeInvalidPhone, "123")
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, ve)
+
+	assert.Equal(t, 400, rec.Code)
+
+	var body struct {
+		Status int              `json:"status"`
+		Detail string           `json:"detail"`
+		Errors []FieldViolation `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 400, body.Status)
+	assert.Equal(t, "validation failed", body.Detail)
+	assert.Equal(t, []FieldViolation{
+		{Field: "email", Code: CodeInvalidEmail, Message: InvalidEmailErr, RejectedValue: "bad"},
+		{Field: "phone", Code: CodeInvalidPhone, Message: InvalidPhoneErr, RejectedValue: "123"},
+	}, body.Errors)
+}
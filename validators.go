@@ -0,0 +1,221 @@
+package siocore
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 1000
+	MaxAgeYears          = 150
+
+	minimumAgeTemplate = "customer must be at least %d years of age"
+)
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// Clock provides the current time to validators that depend on it.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a Clock that always reports t.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time {
+		return t
+	})
+}
+
+// PasswordPolicy describes the requirements a password must meet.
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+}
+
+// DefaultPasswordPolicy matches InvalidPasswordErr: 8 char min, 1 upper, 1 special and 1 numerical.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:      8,
+	RequireUpper:   true,
+	RequireDigit:   true,
+	RequireSpecial: true,
+}
+
+// Validate returns a CodeInvalidPassword AppError if password does not meet the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			special = true
+		}
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength ||
+		(p.RequireUpper && !upper) ||
+		(p.RequireLower && !lower) ||
+		(p.RequireDigit && !digit) ||
+		(p.RequireSpecial && !special) {
+		return NewCodedError(CodeInvalidPassword)
+	}
+
+	return nil
+}
+
+// ValidatePassword validates password against the DefaultPasswordPolicy.
+func ValidatePassword(password string) error {
+	return DefaultPasswordPolicy.Validate(password)
+}
+
+// ValidateEmail returns a CodeInvalidEmail AppError if email is not a valid address.
+func ValidateEmail(email string) error {
+	if !emailRegex.MatchString(email) {
+		return NewCodedError(CodeInvalidEmail)
+	}
+
+	return nil
+}
+
+// ValidateName returns a CodeInvalidName AppError unless name is 1 to MaxNameLength letters,
+// spaces, apostrophes, hyphens or periods.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return NewCodedError(CodeInvalidName)
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !strings.ContainsRune(" '-.", r) {
+			return NewCodedError(CodeInvalidName)
+		}
+	}
+
+	return nil
+}
+
+// ValidateDescription returns a CodeInvalidDescription AppError if description is longer than
+// MaxDescriptionLength or contains control characters other than line breaks and tabs.
+func ValidateDescription(description string) error {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return NewCodedError(CodeInvalidDescription)
+	}
+
+	for _, r := range description {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return NewCodedError(CodeInvalidDescription)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNumericID returns a CodeInvalidID AppError unless id is an unsigned 64-bit integer.
+func ValidateNumericID(id string) error {
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return NewCodedError(CodeInvalidID)
+	}
+
+	return nil
+}
+
+// ValidatePhone returns a CodeInvalidPhone AppError unless phone is a ten digit number.
+// See NormalizePhone for the accepted formats.
+func ValidatePhone(phone string) error {
+	_, err := NormalizePhone(phone)
+	return err
+}
+
+// NormalizePhone returns phone in E.164 format, e.g. "+15551234567". It accepts a ten digit
+// number optionally prefixed with the country code 1 and formatted with spaces, dashes,
+// dots or parentheses.
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" -().", r):
+		default:
+			return "", NewCodedError(CodeInvalidPhone)
+		}
+	}
+
+	number := digits.String()
+	if len(number) == 11 && strings.HasPrefix(number, "1") {
+		number = number[1:]
+	}
+
+	if len(number) != 10 {
+		return "", NewCodedError(CodeInvalidPhone)
+	}
+
+	return "+1" + number, nil
+}
+
+// ValidateDOB returns a CodeInvalidDob AppError if dob is unset, in the future or more than
+// MaxAgeYears ago. A nil clock uses the SystemClock.
+func ValidateDOB(dob time.Time, clock Clock) error {
+	now := clockOrSystem(clock).Now()
+
+	if dob.IsZero() || dob.After(now) || dob.Before(now.AddDate(-MaxAgeYears, 0, 0)) {
+		return NewCodedError(CodeInvalidDob)
+	}
+
+	return nil
+}
+
+// ValidateMinimumAge returns a CodeInvalidAge AppError if someone born on dob is younger than
+// minAge years. A nil clock uses the SystemClock.
+func ValidateMinimumAge(dob time.Time, minAge int, clock Clock) error {
+	now := clockOrSystem(clock).Now()
+
+	if dob.AddDate(minAge, 0, 0).After(now) {
+		appErr := NewCodedError(CodeInvalidAge)
+		appErr.Message = fmt.Sprintf(minimumAgeTemplate, minAge)
+
+		return appErr
+	}
+
+	return nil
+}
+
+// ValidateDateRange returns a CodeInvalidEndDate AppError unless end is after start.
+func ValidateDateRange(start, end time.Time) error {
+	if !end.After(start) {
+		return NewCodedError(CodeInvalidEndDate)
+	}
+
+	return nil
+}
+
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+
+	return clock
+}
@@ -0,0 +1,157 @@
+package siocore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertCode(t *testing.T, err error, code ErrorCode) {
+	t.Helper()
+
+	if code == "" {
+		assert.NoError(t, err)
+		return
+	}
+
+	appErr := &AppError{}
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, code, appErr.ErrorCode)
+		assert.Equal(t, 400, appErr.Code)
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	tt := []struct {
+		email    string
+		expected ErrorCode
+	}{
+		{email: "user@example.com"},
+		{email: "first.last+tag@sub.example.co"},
+		{email: "user@", expected: CodeInvalidEmail},
+		{email: "user.example.com", expected: CodeInvalidEmail},
+		{email: "", expected: CodeInvalidEmail},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.email, func(t *testing.T) {
+			assertCode(t, ValidateEmail(tc.email), tc.expected)
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tt := []struct {
+		name     string
+		password string
+		expected ErrorCode
+	}{
+		{name: "valid", password: "Passw0rd!"},
+		{name: "too short", password: "Pa0!", expected: CodeInvalidPassword},
+		{name: "no upper", password: "passw0rd!", expected: CodeInvalidPassword},
+		{name: "no digit", password: "Password!", expected: CodeInvalidPassword},
+		{name: "no special", password: "Passw0rd", expected: CodeInvalidPassword},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assertCode(t, ValidatePassword(tc.password), tc.expected)
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		assert.EqualError(t, ValidatePassword("weak"), InvalidPasswordErr)
+	})
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 12, RequireLower: true}
+
+	assertCode(t, policy.Validate("lowercaseonly"), "")
+	assertCode(t, policy.Validate("short"), CodeInvalidPassword)
+	assertCode(t, policy.Validate("UPPERCASEONLY"), CodeInvalidPassword)
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tt := []struct {
+		phone    string
+		expected string
+	}{
+		{phone: "5551234567", expected: "+15551234567"},
+		{phone: "(555) 123-4567", expected: "+15551234567"},
+		{phone: "555.123.4567", expected: "+15551234567"},
+		{phone: "+1 555 123 4567", expected: "+15551234567"},
+		{phone: "1-555-123-4567", expected: "+15551234567"},
+		{phone: "555-1234"},
+		{phone: "25551234567"},
+		{phone: "555-123-456a"},
+		{phone: "555+1234567"},
+		{phone: "٠١٢٣٤"},
+		{phone: "٥٥٥١٢٣٤٥٦٧"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.phone, func(t *testing.T) {
+			normalized, err := NormalizePhone(tc.phone)
+			if tc.expected == "" {
+				assertCode(t, err, CodeInvalidPhone)
+				assertCode(t, ValidatePhone(tc.phone), CodeInvalidPhone)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, normalized)
+			assert.NoError(t, ValidatePhone(tc.phone))
+		})
+	}
+}
+
+func TestValidateNameAndDescription(t *testing.T) {
+	assertCode(t, ValidateName("José O'Brien-Smith Jr."), "")
+	assertCode(t, ValidateName("   "), CodeInvalidName)
+	assertCode(t, ValidateName("R2D2"), CodeInvalidName)
+
+	assertCode(t, ValidateDescription("line one\nline two"), "")
+	assertCode(t, ValidateDescription("bell\a"), CodeInvalidDescription)
+}
+
+func TestValidateNumericID(t *testing.T) {
+	assertCode(t, ValidateNumericID("12345"), "")
+	assertCode(t, ValidateNumericID(""), CodeInvalidID)
+	assertCode(t, ValidateNumericID("-1"), CodeInvalidID)
+	assertCode(t, ValidateNumericID("abc"), CodeInvalidID)
+	assertCode(t, ValidateNumericID("+1"), CodeInvalidID)
+	assertCode(t, ValidateNumericID("99999999999999999999999"), CodeInvalidID)
+}
+
+func TestValidateDOB(t *testing.T) {
+	clock := FixedClock(time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+
+	assertCode(t, ValidateDOB(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), clock), "")
+	assertCode(t, ValidateDOB(time.Time{}, clock), CodeInvalidDob)
+	assertCode(t, ValidateDOB(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), clock), CodeInvalidDob)
+	assertCode(t, ValidateDOB(time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC), clock), CodeInvalidDob)
+	assertCode(t, ValidateDOB(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), nil), "")
+}
+
+func TestValidateMinimumAge(t *testing.T) {
+	clock := FixedClock(time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+
+	assertCode(t, ValidateMinimumAge(time.Date(2011, 6, 15, 0, 0, 0, 0, time.UTC), 13, clock), "")
+	assertCode(t, ValidateMinimumAge(time.Date(2011, 6, 16, 0, 0, 0, 0, time.UTC), 13, clock), CodeInvalidAge)
+	assert.EqualError(t, ValidateMinimumAge(time.Date(2011, 6, 16, 0, 0, 0, 0, time.UTC), 13, clock), InvalidAgeErr)
+	assert.EqualError(
+		t,
+		ValidateMinimumAge(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), 18, clock),
+		"customer must be at least 18 years of age",
+	)
+}
+
+func TestValidateDateRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assertCode(t, ValidateDateRange(start, start.Add(time.Minute)), "")
+	assertCode(t, ValidateDateRange(start, start), CodeInvalidEndDate)
+	assert.EqualError(t, ValidateDateRange(start, start.Add(-time.Minute)), InvalidEndDateErr)
+}